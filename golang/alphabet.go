@@ -2,26 +2,68 @@
 //
 // 编码策略：在原始数据前附加 2 字节大端长度前缀，整体视为一个大整数，
 // 再按自定义字符表做进制转换（类似无填充的 Base-N）。
+//
+// 紧凑模式（NewCompactRadixCodec）去掉长度前缀，改用双射进制（见 arithmetic.md 5.2 节）：
+// 字节串按「数字 1~256」的双射 256 进制转为整数，再按「数字 1~N」的双射 N 进制转为字符串。
+// 两步都是一一对应，前导 0x00 字节无需长度即可保留，且任意字符串都能解码。
+//
+// 以上两种模式均为整体大整数转换（时间随长度平方增长），标准模式载荷上限 65535 字节；
+// 大载荷使用分块模式（NewChunkedRadixCodec，见 chunked.go）。
 package idmix
 
 import (
 	"encoding/binary"
 	"errors"
 	"fmt"
+	"math"
 	"math/big"
 )
 
 var errNilCodecFunc = errors.New("codec function is nil")
 
+// ErrPayloadTooLarge 载荷超过标准 RadixCodec 长度前缀可表示的 65535 字节。
+var ErrPayloadTooLarge = errors.New("payload too large")
+
+// maxRadixPayload 为标准模式 2 字节长度前缀可表示的最大载荷。
+const maxRadixPayload = 0xFFFF
+
+// 预置字符表，可直接传给 NewRadixCodec / WithAlphabet，或作为 ShuffleAlphabet 的 base。
+const (
+	// AlphabetURLSafe64 为 RFC 4648 base64url 字符集（A-Z a-z 0-9 - _），URL 中无需转义。
+	AlphabetURLSafe64 = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-_"
+	// AlphabetCrockford32 为 Crockford Base32 字符集（去除 I L O U）。
+	AlphabetCrockford32 = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+	// AlphabetLower36 仅含数字与小写字母，适用于大小写不敏感的场景（域名、文件名）。
+	AlphabetLower36 = "0123456789abcdefghijklmnopqrstuvwxyz"
+	// AlphabetNoLookalike55 为 62 字符去除易混淆的 0 O o 1 I l Q。
+	AlphabetNoLookalike55 = "23456789abcdefghijkmnpqrstuvwxyzABCDEFGHJKLMNPRSTUVWXYZ"
+)
+
 // RadixCodec 使用自定义字符表（Base-N）的二进制↔文本编解码器。
 type RadixCodec struct {
 	base       int
 	chars      []rune
 	fromCustom map[rune]int
+	mode       radixMode
+	// chunkDigits[r] 为分块模式下 r 字节对应的字符数（其他模式为 nil）
+	chunkDigits []int
+	limits      *DecodeLimits // 解码上限（见 WithLimits），nil 为不限
 }
 
-// NewRadixCodec 根据字符表创建 RadixCodec。
-func NewRadixCodec(alphabet string) (*RadixCodec, error) {
+// radixMode 为 RadixCodec 的编码方式。
+type radixMode uint8
+
+const (
+	radixStandard radixMode = iota // 2 字节长度前缀 + 整体进制转换
+	radixCompact                   // 双射进制、无长度前缀
+	radixChunked                   // 定长分块，线性时间（见 chunked.go）
+)
+
+// NewRadixCodec 根据字符表创建 RadixCodec；checks 为可选的字符表校验（见 ValidateAlphabet）。
+func NewRadixCodec(alphabet string, checks ...AlphabetCheck) (*RadixCodec, error) {
+	if err := ValidateAlphabet(alphabet, checks...); err != nil {
+		return nil, err
+	}
 	runes := []rune(alphabet)
 	if len(runes) < 2 {
 		return nil, errors.New("alphabet must have at least 2 unique characters")
@@ -40,6 +82,39 @@ func NewRadixCodec(alphabet string) (*RadixCodec, error) {
 	return rc, nil
 }
 
+// NewCompactRadixCodec 创建紧凑模式的 RadixCodec：不附加 2 字节长度前缀，
+// 典型 ID 短 1~2 个字符。输出与 NewRadixCodec 不兼容。
+func NewCompactRadixCodec(alphabet string, checks ...AlphabetCheck) (*RadixCodec, error) {
+	rc, err := NewRadixCodec(alphabet, checks...)
+	if err != nil {
+		return nil, err
+	}
+	rc.mode = radixCompact
+	return rc, nil
+}
+
+// withAlphabet 以相同模式创建使用新字符表的 RadixCodec。
+func (rc *RadixCodec) withAlphabet(alphabet string) (*RadixCodec, error) {
+	build := NewRadixCodec
+	switch rc.mode {
+	case radixCompact:
+		build = NewCompactRadixCodec
+	case radixChunked:
+		build = NewChunkedRadixCodec
+	}
+	c, err := build(alphabet)
+	if err != nil {
+		return nil, err
+	}
+	c.limits = rc.limits
+	return c, nil
+}
+
+// Compact 报告是否为紧凑模式。
+func (rc *RadixCodec) Compact() bool {
+	return rc.mode == radixCompact
+}
+
 // Alphabet 返回字符表字符串。
 func (rc *RadixCodec) Alphabet() string {
 	return string(rc.chars)
@@ -51,28 +126,78 @@ func (rc *RadixCodec) Base() int {
 }
 
 func (rc *RadixCodec) Encode(data []byte) (string, error) {
+	return rc.encode(nil, data)
+}
+
+// encode 为 Encode 的实现；标准模式使用 sc 中的大整数与缓冲区，sc 为 nil 时临时分配。
+func (rc *RadixCodec) encode(sc *radixScratch, data []byte) (string, error) {
+	switch rc.mode {
+	case radixCompact:
+		return rc.bijectiveString(bijectiveFromBytes(data)), nil
+	case radixChunked:
+		return rc.encodeChunked(data), nil
+	}
+	if len(data) > maxRadixPayload {
+		return "", fmt.Errorf("%w: %d bytes exceeds the %d-byte length prefix; use NewChunkedRadixCodec",
+			ErrPayloadTooLarge, len(data), maxRadixPayload)
+	}
 	if len(data) == 0 {
 		return string(rc.chars[0]), nil
 	}
-	wrapped := make([]byte, 2+len(data))
-	binary.BigEndian.PutUint16(wrapped, uint16(len(data)))
-	copy(wrapped[2:], data)
-	n := new(big.Int).SetBytes(wrapped)
-	return rc.intToString(n), nil
+	if sc == nil {
+		sc = new(radixScratch)
+	}
+	sc.buf = binary.BigEndian.AppendUint16(sc.buf[:0], uint16(len(data)))
+	sc.buf = append(sc.buf, data...)
+	sc.n.SetBytes(sc.buf)
+	return rc.intToString(sc), nil
 }
 
 func (rc *RadixCodec) Decode(s string) ([]byte, error) {
+	return rc.decodeWith(nil, s)
+}
+
+// decodeWith 为 Decode 的实现；标准模式的结果指向 sc 的缓冲区（sc 为 nil 时临时分配），下次使用 sc 前有效。
+func (rc *RadixCodec) decodeWith(sc *radixScratch, s string) ([]byte, error) {
+	if err := rc.precheck(s, rc.limits); err != nil {
+		return nil, err
+	}
+	data, err := rc.decode(sc, s)
+	if err != nil {
+		return nil, err
+	}
+	if err := rc.limits.checkBinary(len(data), false); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func (rc *RadixCodec) decode(sc *radixScratch, s string) ([]byte, error) {
+	switch rc.mode {
+	case radixCompact:
+		n, err := rc.bijectiveInt(s)
+		if err != nil {
+			return nil, err
+		}
+		return bijectiveToBytes(n), nil
+	case radixChunked:
+		return rc.decodeChunked(s)
+	}
 	if s == "" {
 		return nil, errors.New("empty string")
 	}
-	n, err := rc.stringToInt(s)
-	if err != nil {
+	if sc == nil {
+		sc = new(radixScratch)
+	}
+	if err := rc.stringToInt(sc, s); err != nil {
 		return nil, err
 	}
-	raw := n.Bytes()
+	// buf[0] 预留给补位的 0x00：长度前缀的高字节为 0 时大整数会丢掉它
+	size := (sc.n.BitLen() + 7) / 8
+	sc.buf = append(sc.buf[:0], make([]byte, size+1)...)
+	sc.n.FillBytes(sc.buf[1:])
 	for pad := 0; pad <= 1; pad++ {
-		buf := make([]byte, pad+len(raw))
-		copy(buf[pad:], raw)
+		buf := sc.buf[1-pad:]
 		if len(buf) < 2 {
 			continue
 		}
@@ -85,15 +210,122 @@ func (rc *RadixCodec) Decode(s string) ([]byte, error) {
 	return nil, errors.New("invalid encoded data length")
 }
 
-func (rc *RadixCodec) intToString(n *big.Int) string {
+// radixScratch 为标准模式进制转换复用的大整数与缓冲区，零值可用，不可并发使用。
+// 单次 Encode / Decode 临时分配一个，批量接口每个 worker 复用一个（见 batch.go）。
+type radixScratch struct {
+	n, rem, word big.Int
+	// chunk = base^chunkDigits 为不超过 uint64 的最大幂，每次大整数乘除处理 chunkDigits 个字符
+	base        int
+	chunk       big.Int
+	chunkVal    uint64
+	chunkDigits int
+	buf         []byte
+	chars       []rune
+}
+
+// setBase 按 base 计算 chunk（已为同一 base 计算过时不重复）。
+func (sc *radixScratch) setBase(base int) {
+	if sc.base == base {
+		return
+	}
+	b := uint64(base)
+	p, k := uint64(1), 0
+	for p <= math.MaxUint64/b {
+		p *= b
+		k++
+	}
+	sc.base, sc.chunkVal, sc.chunkDigits = base, p, k
+	sc.chunk.SetUint64(p)
+}
+
+// intToString 将 sc.n 写成 base 进制字符串（会修改 sc.n）：每次除以 chunk，余数在 uint64 内展开为 chunkDigits 位。
+func (rc *RadixCodec) intToString(sc *radixScratch) string {
+	n := &sc.n
 	if n.Sign() == 0 {
 		return string(rc.chars[0])
 	}
+	sc.setBase(rc.base)
+	base := uint64(rc.base)
+	chars := sc.chars[:0]
+	for n.Sign() > 0 {
+		n.QuoRem(n, &sc.chunk, &sc.rem)
+		w := sc.rem.Uint64()
+		// 非最高组补足 chunkDigits 位（含零），最高组去掉前导零
+		for i := 0; i < sc.chunkDigits && (w > 0 || n.Sign() > 0); i++ {
+			chars = append(chars, rc.chars[w%base])
+			w /= base
+		}
+	}
+	for i, j := 0, len(chars)-1; i < j; i, j = i+1, j-1 {
+		chars[i], chars[j] = chars[j], chars[i]
+	}
+	sc.chars = chars
+	return string(chars)
+}
+
+// stringToInt 将 s 解析到 sc.n：每 chunkDigits 个字符先在 uint64 内累加，再做一次大整数乘加。
+func (rc *RadixCodec) stringToInt(sc *radixScratch, s string) error {
+	sc.setBase(rc.base)
+	n := sc.n.SetInt64(0)
+	base := uint64(rc.base)
+	w, p := uint64(0), uint64(1)
+	for _, r := range s {
+		idx, ok := rc.fromCustom[r]
+		if !ok {
+			return fmt.Errorf("invalid character %q", r)
+		}
+		w = w*base + uint64(idx)
+		p *= base
+		if p == sc.chunkVal {
+			n.Mul(n, &sc.chunk)
+			n.Add(n, sc.word.SetUint64(w))
+			w, p = 0, 1
+		}
+	}
+	if p > 1 {
+		n.Mul(n, sc.word.SetUint64(p))
+		n.Add(n, sc.rem.SetUint64(w))
+	}
+	return nil
+}
+
+// bijectiveFromBytes 将 data 视为数字 1~256（字节值 + 1）的双射 256 进制数。
+func bijectiveFromBytes(data []byte) *big.Int {
+	n := new(big.Int)
+	digit := new(big.Int)
+	for _, b := range data {
+		n.Lsh(n, 8)
+		n.Add(n, digit.SetInt64(int64(b)+1))
+	}
+	return n
+}
+
+// bijectiveToBytes 为 bijectiveFromBytes 的逆操作（会修改 n）。
+func bijectiveToBytes(n *big.Int) []byte {
+	one := big.NewInt(1)
+	var out []byte
+	for n.Sign() > 0 {
+		n.Sub(n, one)
+		out = append(out, byte(n.Uint64()&0xFF))
+		n.Rsh(n, 8)
+	}
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	if out == nil {
+		out = []byte{}
+	}
+	return out
+}
+
+// bijectiveString 将 n 写成数字 1~N 的双射 N 进制，数字 d 对应 chars[d-1]（会修改 n）。
+func (rc *RadixCodec) bijectiveString(n *big.Int) string {
 	base := big.NewInt(int64(rc.base))
-	zero := big.NewInt(0)
+	one := big.NewInt(1)
 	rem := new(big.Int)
-	chars := make([]rune, 0, 32)
-	for n.Cmp(zero) > 0 {
+	var chars []rune
+	for n.Sign() > 0 {
+		n.Sub(n, one)
 		n.DivMod(n, base, rem)
 		chars = append(chars, rc.chars[rem.Int64()])
 	}
@@ -103,16 +335,17 @@ func (rc *RadixCodec) intToString(n *big.Int) string {
 	return string(chars)
 }
 
-func (rc *RadixCodec) stringToInt(s string) (*big.Int, error) {
+func (rc *RadixCodec) bijectiveInt(s string) (*big.Int, error) {
 	n := big.NewInt(0)
 	base := big.NewInt(int64(rc.base))
+	digit := new(big.Int)
 	for _, r := range s {
 		idx, ok := rc.fromCustom[r]
 		if !ok {
 			return nil, fmt.Errorf("invalid character %q", r)
 		}
 		n.Mul(n, base)
-		n.Add(n, big.NewInt(int64(idx)))
+		n.Add(n, digit.SetInt64(int64(idx)+1))
 	}
 	return n, nil
 }
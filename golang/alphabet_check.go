@@ -0,0 +1,185 @@
+// alphabet_check.go 提供可选的字符表校验，拒绝输出可能被 URL、复制粘贴
+// 或 Unicode 规范化破坏的字符表。
+//
+// 校验均为显式开启：NewRadixCodec / WithAlphabet 不传 AlphabetCheck 时行为不变。
+// NFCStable 需要 Unicode 规范化数据，实现位于子包 idmixnfc（依赖 golang.org/x/text），
+// 以免核心包的所有使用者引入该依赖；使用时匿名导入该子包即可。
+package idmix
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"unicode"
+)
+
+// AlphabetCheck 为字符表校验模式，可按位组合。
+type AlphabetCheck uint
+
+const (
+	// StrictURLSafe 仅允许 RFC 3986 unreserved 字符（A-Z a-z 0-9 - . _ ~），输出无需百分号编码。
+	StrictURLSafe AlphabetCheck = 1 << iota
+	// NoConfusables 拒绝空白、控制与格式字符（含零宽字符）、组合符号，
+	// 以及同一字符表内外观相同的字符组（如 0/O/o、1/l/I、拉丁 a 与西里尔 а）。
+	NoConfusables
+	// NFCStable 要求每个字符在 NFC 规范化下保持不变，且不会与前一个字符组合；
+	// 满足时任意输出串经 NFC 规范化后不变。须匿名导入 idmixnfc，否则返回 ErrNFCUnavailable。
+	NFCStable
+)
+
+var alphabetCheckNames = []struct {
+	check AlphabetCheck
+	name  string
+}{
+	{StrictURLSafe, "strictURLSafe"},
+	{NoConfusables, "noConfusables"},
+	{NFCStable, "nfcStable"},
+}
+
+func (c AlphabetCheck) String() string {
+	var names []string
+	for _, n := range alphabetCheckNames {
+		if c&n.check != 0 {
+			names = append(names, n.name)
+			c &^= n.check
+		}
+	}
+	if c != 0 {
+		names = append(names, fmt.Sprintf("AlphabetCheck(%#x)", uint(c)))
+	}
+	if len(names) == 0 {
+		return "none"
+	}
+	return strings.Join(names, "|")
+}
+
+// ParseAlphabetCheck 按名称（strictURLSafe、noConfusables、nfcStable）解析校验模式。
+func ParseAlphabetCheck(name string) (AlphabetCheck, error) {
+	for _, n := range alphabetCheckNames {
+		if n.name == name {
+			return n.check, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown alphabet check %q", name)
+}
+
+// ErrNFCUnavailable 未注册 NFCStable 的实现。
+var ErrNFCUnavailable = errors.New(`nfcStable check unavailable: import _ "github.com/Vanni-Fan/idmix/golang/idmixnfc"`)
+
+// nfcStable 为 RegisterNFCStable 注册的单字符判定函数。
+var nfcStable atomic.Pointer[func(r rune) bool]
+
+// RegisterNFCStable 注册 NFCStable 的判定函数：字符在 NFC 下不变且不会与前一字符合成时返回 true。
+// 由 idmixnfc 在 init 中调用，一般无需直接使用。
+func RegisterNFCStable(stable func(r rune) bool) {
+	if stable == nil {
+		panic("idmix: RegisterNFCStable with nil func")
+	}
+	nfcStable.Store(&stable)
+}
+
+// AlphabetError 描述未通过某项校验的字符。
+type AlphabetError struct {
+	Check  AlphabetCheck
+	Reason string
+	Chars  []rune
+}
+
+func (e *AlphabetError) Error() string {
+	parts := make([]string, len(e.Chars))
+	for i, r := range e.Chars {
+		parts[i] = fmt.Sprintf("%q (U+%04X)", r, r)
+	}
+	return fmt.Sprintf("alphabet fails %s: %s: %s", e.Check, e.Reason, strings.Join(parts, ", "))
+}
+
+// ValidateAlphabet 按 checks 校验字符表，每项失败对应一个 *AlphabetError（以 errors.Join 合并）。
+func ValidateAlphabet(alphabet string, checks ...AlphabetCheck) error {
+	var mode AlphabetCheck
+	for _, c := range checks {
+		mode |= c
+	}
+	runes := []rune(alphabet)
+	var errs []error
+	if mode&StrictURLSafe != 0 {
+		errs = appendAlphabetError(errs, StrictURLSafe, "not an RFC 3986 unreserved character",
+			filterRunes(runes, func(r rune) bool { return !isURLUnreserved(r) }))
+	}
+	if mode&NoConfusables != 0 {
+		errs = appendAlphabetError(errs, NoConfusables, "invisible, whitespace or combining character",
+			filterRunes(runes, isInvisibleOrCombining))
+		for _, group := range confusableGroups(runes) {
+			errs = appendAlphabetError(errs, NoConfusables, "characters look alike", group)
+		}
+	}
+	if mode&NFCStable != 0 {
+		if stable := nfcStable.Load(); stable == nil {
+			errs = append(errs, ErrNFCUnavailable)
+		} else {
+			errs = appendAlphabetError(errs, NFCStable, "changes or combines under NFC normalization",
+				filterRunes(runes, func(r rune) bool { return !(*stable)(r) }))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func appendAlphabetError(errs []error, check AlphabetCheck, reason string, chars []rune) []error {
+	if len(chars) == 0 {
+		return errs
+	}
+	return append(errs, &AlphabetError{Check: check, Reason: reason, Chars: chars})
+}
+
+func filterRunes(runes []rune, bad func(rune) bool) []rune {
+	var out []rune
+	for _, r := range runes {
+		if bad(r) {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+func isURLUnreserved(r rune) bool {
+	switch {
+	case r >= 'A' && r <= 'Z', r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+		return true
+	}
+	return r == '-' || r == '.' || r == '_' || r == '~'
+}
+
+func isInvisibleOrCombining(r rune) bool {
+	return unicode.IsSpace(r) || !unicode.IsGraphic(r) || unicode.Is(unicode.M, r)
+}
+
+// lookalikeSets 为外观相同或极易混淆的字符组：数字与字母，以及拉丁字母与西里尔/希腊同形字。
+var lookalikeSets = []string{
+	"0Oo\u041e\u043e\u039f\u03bf",  // 0 O o 与西里尔 О о、希腊 Ο ο
+	"1lI|\u0406\u0456\u0399\u01c0", // 1 l I | 与西里尔 І і、希腊 Ι、ǀ
+	"a\u0430", "A\u0410\u0391", "B\u0412\u0392", "c\u0441", "C\u0421\u03f9",
+	"e\u0435", "E\u0415\u0395", "H\u041d\u0397", "j\u0458", "J\u0408",
+	"K\u041a\u039a\u212a", "M\u041c\u039c", "N\u039d", "p\u0440", "P\u0420\u03a1",
+	"s\u0455", "S\u0405", "T\u0422\u03a4", "x\u0445", "X\u0425\u03a7",
+	"y\u0443", "Y\u03a5\u04ae", "Z\u0396",
+}
+
+func confusableGroups(runes []rune) [][]rune {
+	present := make(map[rune]bool, len(runes))
+	for _, r := range runes {
+		present[r] = true
+	}
+	var groups [][]rune
+	for _, set := range lookalikeSets {
+		var group []rune
+		for _, r := range set {
+			if present[r] {
+				group = append(group, r)
+			}
+		}
+		if len(group) > 1 {
+			groups = append(groups, group)
+		}
+	}
+	return groups
+}
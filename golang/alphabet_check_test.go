@@ -0,0 +1,123 @@
+// alphabet_check_test.go 覆盖 StrictURLSafe、NoConfusables 字符表校验；NFCStable 的判定见 idmixnfc。
+package idmix
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestValidateAlphabetAccepts(t *testing.T) {
+	cases := []struct {
+		name     string
+		alphabet string
+		checks   []AlphabetCheck
+	}{
+		{"default_url", DefaultAlphabet, []AlphabetCheck{StrictURLSafe}},
+		{"url64_url", AlphabetURLSafe64, []AlphabetCheck{StrictURLSafe}},
+		{"no_lookalike_all", AlphabetNoLookalike55, []AlphabetCheck{StrictURLSafe, NoConfusables}},
+		{"crockford_all", AlphabetCrockford32, []AlphabetCheck{StrictURLSafe | NoConfusables}},
+		{"chinese", "一二三四五六七八九十", []AlphabetCheck{NoConfusables}},
+		{"no_checks", " \u200b", nil},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if err := ValidateAlphabet(c.alphabet, c.checks...); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestValidateAlphabetRejects(t *testing.T) {
+	cases := []struct {
+		name     string
+		alphabet string
+		check    AlphabetCheck
+		chars    string
+	}{
+		{"url_reserved", "abc+/=", StrictURLSafe, "+/="},
+		{"url_non_ascii", "abcé", StrictURLSafe, "é"},
+		{"zero_width", "ab\u200bc", NoConfusables, "\u200b"},
+		{"whitespace", "ab c\t", NoConfusables, " \t"},
+		{"combining", "ab\u0301", NoConfusables, "\u0301"},
+		{"digit_letter", DefaultAlphabet, NoConfusables, "0Oo"},
+		{"cyrillic_homoglyph", "abc\u0430", NoConfusables, "a\u0430"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := ValidateAlphabet(c.alphabet, c.check)
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			var ae *AlphabetError
+			if !errors.As(err, &ae) || ae.Check != c.check {
+				t.Fatalf("got %v", err)
+			}
+			if string(ae.Chars) != c.chars {
+				t.Fatalf("offending chars %q, want %q", string(ae.Chars), c.chars)
+			}
+			t.Logf("%q => %v", c.alphabet, err)
+		})
+	}
+
+	err := ValidateAlphabet(DefaultAlphabet, NoConfusables)
+	if !strings.Contains(err.Error(), `'1' (U+0031), 'l' (U+006C), 'I' (U+0049)`) {
+		t.Fatalf("error should list every lookalike group: %v", err)
+	}
+}
+
+// TestAlphabetChecksOutput 通过校验的字符表，其编码输出在 URL 转义下保持不变。
+func TestAlphabetChecksOutput(t *testing.T) {
+	if _, err := NewRadixCodec("ab c", StrictURLSafe); err == nil {
+		t.Fatal("NewRadixCodec should apply checks")
+	}
+	if _, err := New(WithAlphabet("ab\u200bc", NoConfusables)); err == nil {
+		t.Fatal("WithAlphabet should apply checks")
+	}
+
+	m, err := New(WithAlphabet(AlphabetURLSafe64, StrictURLSafe))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 200; i++ {
+		s, err := m.Encode(uint32(i*7919), "k")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if url.PathEscape(s) != s || url.QueryEscape(s) != s {
+			t.Fatalf("%q changes under URL escaping", s)
+		}
+	}
+}
+
+func TestAlphabetCheckNames(t *testing.T) {
+	for _, name := range []string{"strictURLSafe", "noConfusables", "nfcStable"} {
+		c, err := ParseAlphabetCheck(name)
+		if err != nil || c.String() != name {
+			t.Fatalf("%s: %v %v", name, c, err)
+		}
+	}
+	if got := (StrictURLSafe | NFCStable).String(); got != "strictURLSafe|nfcStable" {
+		t.Fatalf("String() = %q", got)
+	}
+	if _, err := ParseAlphabetCheck("bogus"); err == nil {
+		t.Fatal("expected unknown check error")
+	}
+}
+
+// TestNFCUnavailable 未导入 idmixnfc 时 NFCStable 明确报错，而不是静默通过。
+func TestNFCUnavailable(t *testing.T) {
+	if nfcStable.Load() != nil {
+		t.Skip("NFCStable registered")
+	}
+	err := ValidateAlphabet(DefaultAlphabet, StrictURLSafe|NFCStable)
+	if !errors.Is(err, ErrNFCUnavailable) {
+		t.Fatalf("got %v", err)
+	}
+	if _, err := NewRadixCodec(DefaultAlphabet, NFCStable); !errors.Is(err, ErrNFCUnavailable) {
+		t.Fatalf("NewRadixCodec: %v", err)
+	}
+	t.Logf("%v", err)
+}
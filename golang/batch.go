@@ -0,0 +1,153 @@
+// batch.go 实现批量编解码：一次调用处理一组条目，可选地分给有界数量的 worker 并发执行，
+// 单个条目失败不影响其他条目。
+//
+// 调度按连续的小块（batchChunk 个条目）领取，避免每个条目一次 goroutine 或 channel 往返；
+// 结果切片一次分配，按输入下标写入，输出顺序与输入一致。每个 worker 持有一份 batchScratch，
+// 条目间复用对象切片、二进制块与 RadixCodec 的大整数和缓冲区，省去逐条调用 Encode/Decode 时的这些分配。
+package idmix
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// batchChunk 为 worker 每次领取的条目数。
+const batchChunk = 64
+
+// BatchOption 配置 EncodeBatch / DecodeBatch。
+type BatchOption func(*batchConfig) error
+
+type batchConfig struct {
+	workers int
+}
+
+// WithWorkers 设置并发 worker 数。默认 1，即在调用方 goroutine 中顺序执行；
+// n ≤ 0 时取 runtime.GOMAXPROCS(0)。条目少于两块时不启动 worker。
+func WithWorkers(n int) BatchOption {
+	return func(c *batchConfig) error {
+		if n <= 0 {
+			n = runtime.GOMAXPROCS(0)
+		}
+		c.workers = n
+		return nil
+	}
+}
+
+// BatchError 表示批量操作中有条目失败；Errs 与输入等长，成功的条目为 nil。
+// 可用 errors.Is / errors.As 匹配任一条目的错误。
+type BatchError struct {
+	Errs   []error
+	failed int
+}
+
+func (e *BatchError) Error() string {
+	for i, err := range e.Errs {
+		if err != nil {
+			return fmt.Sprintf("%d of %d items failed; first: item %d: %v", e.failed, len(e.Errs), i, err)
+		}
+	}
+	return "batch failed"
+}
+
+// Failed 返回失败条目的下标（升序）。
+func (e *BatchError) Failed() []int {
+	idx := make([]int, 0, e.failed)
+	for i, err := range e.Errs {
+		if err != nil {
+			idx = append(idx, i)
+		}
+	}
+	return idx
+}
+
+// Unwrap 返回各失败条目的错误，供 errors.Is / errors.As 使用。
+func (e *BatchError) Unwrap() []error {
+	errs := make([]error, 0, e.failed)
+	for _, err := range e.Errs {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// batchScratch 为单个 worker 在条目间复用的缓冲区，不可并发使用。
+type batchScratch struct {
+	objects []dataObject
+	block   []byte
+	radix   radixScratch
+}
+
+// EncodeBatch 对每组值编码，结果与 Encode 相同，与 values 按下标对应。
+// 有条目失败时其余结果照常返回，失败条目为空串，错误为 *BatchError。
+func (m *IdMix) EncodeBatch(values [][]any, opts ...BatchOption) ([]string, error) {
+	out := make([]string, len(values))
+	err := runBatch(len(values), opts, func(sc *batchScratch, i int) (err error) {
+		out[i], err = m.encode(sc, values[i])
+		return err
+	})
+	return out, err
+}
+
+// DecodeBatch 对每个编码串解码，结果与 Decode 相同，与 encoded 按下标对应。
+// 有条目失败时其余结果照常返回，失败条目为 nil，错误为 *BatchError。
+func (m *IdMix) DecodeBatch(encoded []string, opts ...BatchOption) ([][]any, error) {
+	out := make([][]any, len(encoded))
+	err := runBatch(len(encoded), opts, func(sc *batchScratch, i int) (err error) {
+		_, out[i], err = m.decodeObserved(sc, OpDecode, encoded[i])
+		return err
+	})
+	return out, err
+}
+
+// runBatch 对 [0, n) 的每个下标调用 fn，汇总失败条目；fn 只写入自己下标的结果，
+// sc 为当前 worker 独占的缓冲区。
+func runBatch(n int, opts []BatchOption, fn func(sc *batchScratch, i int) error) error {
+	cfg := batchConfig{workers: 1}
+	for _, opt := range opts {
+		if err := opt(&cfg); err != nil {
+			return err
+		}
+	}
+
+	errs := make([]error, n)
+	var failed atomic.Int64
+	run := func(sc *batchScratch, start, end int) {
+		for i := start; i < end; i++ {
+			if err := fn(sc, i); err != nil {
+				errs[i] = err
+				failed.Add(1)
+			}
+		}
+	}
+
+	workers := min(cfg.workers, (n+batchChunk-1)/batchChunk)
+	if workers <= 1 {
+		run(&batchScratch{}, 0, n)
+	} else {
+		var next atomic.Int64
+		var wg sync.WaitGroup
+		wg.Add(workers)
+		for w := 0; w < workers; w++ {
+			go func() {
+				defer wg.Done()
+				var sc batchScratch
+				for {
+					start := int(next.Add(batchChunk)) - batchChunk
+					if start >= n {
+						return
+					}
+					run(&sc, start, min(start+batchChunk, n))
+				}
+			}()
+		}
+		wg.Wait()
+	}
+
+	if f := failed.Load(); f > 0 {
+		return &BatchError{Errs: errs, failed: int(f)}
+	}
+	return nil
+}
@@ -0,0 +1,168 @@
+// batch_test.go 覆盖批量编解码：顺序与并发结果一致、单条失败不影响其他条目，以及逐条循环与批量（复用缓冲区）的基准。
+package idmix
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func batchValues(n int) [][]any {
+	values := make([][]any, n)
+	for i := range values {
+		values[i] = []any{uint32(i), "eu", int64(-1 - i)}
+	}
+	return values
+}
+
+// TestBatchRoundTrip 不同 worker 数下结果按下标对应且可逐条解码。
+func TestBatchRoundTrip(t *testing.T) {
+	m, _ := New()
+	values := batchValues(1000)
+	for _, workers := range []int{1, 2, 7, 0} {
+		encoded, err := m.EncodeBatch(values, WithWorkers(workers))
+		if err != nil {
+			t.Fatal(err)
+		}
+		decoded, err := m.DecodeBatch(encoded, WithWorkers(workers))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := range values {
+			if !reflect.DeepEqual(decoded[i], values[i]) {
+				t.Fatalf("workers=%d item %d: %v, want %v", workers, i, decoded[i], values[i])
+			}
+			if single, _ := m.Decode(encoded[i]); !reflect.DeepEqual(single, values[i]) {
+				t.Fatalf("workers=%d item %d: %q does not decode on its own", workers, i, encoded[i])
+			}
+		}
+	}
+	if out, err := m.EncodeBatch(nil); err != nil || len(out) != 0 {
+		t.Fatalf("empty batch: %v %v", out, err)
+	}
+}
+
+// TestBatchScratchReuse 长短交替的条目复用同一份缓冲区时，结果仍与逐条 Encode/Decode 一致。
+func TestBatchScratchReuse(t *testing.T) {
+	for _, alphabet := range []string{DefaultAlphabet, "0123456789abcdef"} {
+		m, err := New(WithAlphabet(alphabet))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var values [][]any
+		for i := 0; i < 300; i++ {
+			switch i % 3 {
+			case 0:
+				values = append(values, []any{uint64(1<<63 + uint64(i)), "0123456789abcdef0123456789abcdef", int64(-1 - i)})
+			case 1:
+				values = append(values, []any{uint8(i)})
+			default:
+				values = append(values, []any{int16(-i), "x"})
+			}
+		}
+		encoded, err := m.EncodeBatch(values)
+		if err != nil {
+			t.Fatal(err)
+		}
+		decoded, err := m.DecodeBatch(encoded)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, s := range encoded {
+			single, err := m.Decode(s)
+			if err != nil || !reflect.DeepEqual(single, values[i]) || !reflect.DeepEqual(decoded[i], values[i]) {
+				t.Fatalf("%s item %d: %q => %v / %v (%v), want %v", alphabet, i, s, single, decoded[i], err, values[i])
+			}
+		}
+		t.Logf("alphabet %q: %d mixed-length items match", alphabet, len(values))
+	}
+}
+
+// TestBatchErrors 失败条目不影响其他条目；BatchError 给出下标并可用 errors.Is 匹配条目错误。
+func TestBatchErrors(t *testing.T) {
+	m, _ := New(WithDecodeLimits(DecodeLimits{MaxChars: 32}))
+	values := batchValues(300)
+	values[5] = nil                   // 没有值
+	values[130] = []any{3.14}         // 不支持的类型
+	values[299] = []any{"", uint8(1)} // 空字符串
+	for _, workers := range []int{1, 4} {
+		encoded, err := m.EncodeBatch(values, WithWorkers(workers))
+		var be *BatchError
+		if !errors.As(err, &be) || !reflect.DeepEqual(be.Failed(), []int{5, 130, 299}) || len(be.Errs) != 300 {
+			t.Fatalf("workers=%d: %v", workers, err)
+		}
+		if encoded[5] != "" || encoded[4] == "" || encoded[298] == "" {
+			t.Fatalf("workers=%d: unexpected results around failures", workers)
+		}
+		t.Logf("workers=%d encode: %v", workers, err)
+
+		encoded[7] = "!!"
+		encoded[200] = string(make([]byte, 100))
+		decoded, err := m.DecodeBatch(encoded, WithWorkers(workers))
+		if !errors.As(err, &be) || !reflect.DeepEqual(be.Failed(), []int{5, 7, 130, 200, 299}) {
+			t.Fatalf("workers=%d: %v", workers, err)
+		}
+		if !errors.Is(err, ErrLimitExceeded) {
+			t.Fatalf("errors.Is should see item errors: %v", err)
+		}
+		if decoded[7] != nil || !reflect.DeepEqual(decoded[8], values[8]) {
+			t.Fatalf("workers=%d: unexpected results around failures", workers)
+		}
+	}
+}
+
+// BenchmarkEncodeBatch 对比逐条循环与不同 worker 数的批量编码（4096 条/批，ns/op 为每批耗时）。
+func BenchmarkEncodeBatch(b *testing.B) {
+	m, _ := New()
+	values := batchValues(4096)
+	b.Run("loop", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			for _, v := range values {
+				if _, err := m.Encode(v...); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+	for _, workers := range benchWorkers {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := m.EncodeBatch(values, WithWorkers(workers)); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkDecodeBatch 同 BenchmarkEncodeBatch，解码方向。
+func BenchmarkDecodeBatch(b *testing.B) {
+	m, _ := New()
+	encoded, _ := m.EncodeBatch(batchValues(4096))
+	b.Run("loop", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			for _, s := range encoded {
+				if _, err := m.Decode(s); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+	for _, workers := range benchWorkers {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := m.DecodeBatch(encoded, WithWorkers(workers)); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// benchWorkers 为基准测试的 worker 数；配合 -cpu 1,2,4,8 观察扩展性。
+var benchWorkers = []int{1, 2, 4, 8}
@@ -298,6 +298,14 @@ func TestCompareSqids(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
+	compactCodec, err := NewCompactRadixCodec(DefaultAlphabet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	idmixCompact, err := New(WithCodec(compactCodec))
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	sqidsDefault, err := sqids.New()
 	if err != nil {
@@ -312,10 +320,11 @@ func TestCompareSqids(t *testing.T) {
 	t.Log("  idmix vs sqids-go  编码长度对比")
 	t.Log("  说明: sqids 仅支持非负整数; idmix 长度含 32 态变体(报告 min/max/avg)")
 	t.Log("  字母表: idmix 默认 62 字符; sqids 默认 64 字符; 另附双方均用 62 字符对比")
+	t.Log("  idmix紧凑: NewCompactRadixCodec(默认 62 字符), 无 2 字节长度前缀")
 	t.Log("══════════════════════════════════════════════════════════════")
 
-	header := fmt.Sprintf("%-28s | %-12s | %-12s | %-12s | %-12s",
-		"场景", "sqids默认", "idmix默认", "sqids@62", "idmix@62")
+	header := fmt.Sprintf("%-28s | %-12s | %-12s | %-12s | %-12s | %-12s",
+		"场景", "sqids默认", "idmix默认", "sqids@62", "idmix@62", "idmix紧凑")
 	t.Log(header)
 	t.Log(strings.Repeat("-", len(header)))
 
@@ -333,13 +342,21 @@ func TestCompareSqids(t *testing.T) {
 		if err != nil {
 			t.Fatal(err)
 		}
+		iCompact, err := measureIdmixLength(idmixCompact, values...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if iCompact.max > iDef.max {
+			t.Fatalf("%s: compact max %v > default max %v", c.name, iCompact.max, iDef.max)
+		}
 
-		t.Logf("%-28s | %4.0f (%-5s) | %4.0f~%-4.0f(%-5s) | %4.0f (%-5s) | %4.0f~%-4.0f(%-5s)",
+		t.Logf("%-28s | %4.0f (%-5s) | %4.0f~%-4.0f(%-5s) | %4.0f (%-5s) | %4.0f~%-4.0f(%-5s) | %4.0f~%-4.0f(%-5s)",
 			c.name,
 			sDef.avg, truncSample(sDef.sample, 8),
 			iDef.min, iDef.max, truncSample(iDef.sample, 8),
 			s62.avg, truncSample(s62.sample, 8),
 			i62.min, i62.max, truncSample(i62.sample, 8),
+			iCompact.min, iCompact.max, truncSample(iCompact.sample, 8),
 		)
 	}
 
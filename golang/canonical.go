@@ -0,0 +1,105 @@
+// canonical.go 实现跨 variant 的规范化与相等比较。
+//
+// Encode 随机选取 variant，同一组值最多有 maxVariants 个合法编码串；按字符串去重或查表会失效。
+// Canonical 把任意 variant 的编码串改写为固定 variant 的形式，Equal 比较解码内容，
+// Fingerprint 给出与 variant 及文本层无关的稳定哈希，可直接用作 map 或数据库的键。
+package idmix
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+)
+
+// fingerprintDomain 为 Fingerprint 的哈希域前缀，避免与其他 SHA-256 用途混淆。
+const fingerprintDomain = "idmix/fingerprint/v1\x00"
+
+// WithCanonicalVariant 设置 Canonical 输出使用的 variant（默认 0）。
+// 设置了 tagBits 时 v 为 tag 内的随机部分，须小于 maxVariants / 2^tagBits，tag 保持不变。
+func WithCanonicalVariant(v int) Option {
+	return func(m *IdMix) error {
+		if v < 0 {
+			return fmt.Errorf("canonical variant %d cannot be negative", v)
+		}
+		m.canonicalVariant = v
+		return nil
+	}
+}
+
+// CanonicalVariant 返回 Canonical 输出使用的 variant（tag 内的随机部分）。
+func (m *IdMix) CanonicalVariant() int { return m.canonicalVariant }
+
+// Canonical 将 s 改写为规范 variant 的编码串；同一组值（及 tag）的任意 variant 得到相同结果。
+func (m *IdMix) Canonical(s string) (string, error) {
+	tag, ttl, objects, err := m.decodeContent(s)
+	if err != nil {
+		return "", err
+	}
+	data, err := m.idx.encodeBlock(objects, tag*m.idx.tagSpan()+m.canonicalVariant, ttl)
+	if err != nil {
+		return "", err
+	}
+	return m.codec.Encode(data)
+}
+
+// Equal 报告 a 与 b 是否解码为相同的值（类型、顺序与 tag 均相同）；任一无法解码时为 false。
+func (m *IdMix) Equal(a, b string) bool {
+	ka, err := m.contentKey(a)
+	if err != nil {
+		return false
+	}
+	kb, err := m.contentKey(b)
+	return err == nil && bytes.Equal(ka, kb)
+}
+
+// Fingerprint 返回 s 解码内容的稳定哈希（32 个十六进制字符，SHA-256 前 128 位）。
+//
+// 结果只取决于 kind、tag 与值，与 variant、Codec、字符表、校验方式及版本标记无关，
+// 因此更换文本层或迁移 header 配置后仍可用作同一实体的键。
+func (m *IdMix) Fingerprint(s string) (string, error) {
+	key, err := m.contentKey(s)
+	if err != nil {
+		return "", err
+	}
+	h := sha256.New()
+	h.Write([]byte(fingerprintDomain))
+	h.Write([]byte(m.idx.kind))
+	h.Write([]byte{0})
+	h.Write(key)
+	return hex.EncodeToString(h.Sum(nil)[:16]), nil
+}
+
+// decodeContent 解码 s 并返回 tag、有效期标记与数据对象。
+func (m *IdMix) decodeContent(s string) (tag int, ttl bool, objects []dataObject, err error) {
+	data, values, err := m.decodeObserved(nil, OpCanonical, s)
+	if err != nil {
+		return 0, false, nil, err
+	}
+	if tag, err = m.idx.TagOf(data); err != nil {
+		return 0, false, nil, err
+	}
+	objects, err = normalizeObjects(values)
+	return tag, m.idx.hasTTL(data), objects, err
+}
+
+// contentKey 为 tag 字节后接各对象的最短编码（未加掩码），与 variant 及 header 配置无关。
+// 带有效期标记时 tag 字节置最高位（tag 不超过 5 位），与同值的普通编码串区分。
+func (m *IdMix) contentKey(s string) ([]byte, error) {
+	tag, ttl, objects, err := m.decodeContent(s)
+	if err != nil {
+		return nil, err
+	}
+	key := []byte{byte(tag)}
+	if ttl {
+		key[0] |= 0x80
+	}
+	for _, obj := range objects {
+		ob, err := encodeObject(obj)
+		if err != nil {
+			return nil, err
+		}
+		key = append(key, ob...)
+	}
+	return key, nil
+}
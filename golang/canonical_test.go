@@ -0,0 +1,160 @@
+// canonical_test.go 覆盖跨 variant 的规范化：Canonical 收敛到同一编码串、Equal 比较内容、
+// Fingerprint 与 variant 及文本层无关。
+package idmix
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestCanonicalAcrossVariants 同一组值的全部 variant 规范化为同一编码串，且结果幂等。
+func TestCanonicalAcrossVariants(t *testing.T) {
+	values := []any{uint32(1001), "eu", int64(-5)}
+	for _, cv := range []int{0, 5, 31} {
+		m, err := New(WithCanonicalVariant(cv))
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, _ := m.EncodeWithVariant(cv, values...)
+		for v := 0; v < m.Idx().MaxVariants(); v++ {
+			s, _ := m.EncodeWithVariant(v, values...)
+			got, err := m.Canonical(s)
+			if err != nil || got != want {
+				t.Fatalf("cv=%d variant %d: %q => %q %v, want %q", cv, v, s, got, err, want)
+			}
+			if again, _ := m.Canonical(got); again != got {
+				t.Fatalf("Canonical is not idempotent: %q => %q", got, again)
+			}
+		}
+		t.Logf("canonical variant %2d => %s", cv, want)
+	}
+
+	// 文本层的非规范写法（前导零字符）同样收敛
+	m, _ := New()
+	s, _ := m.EncodeWithVariant(7, uint8(10))
+	want, _ := m.EncodeWithVariant(0, uint8(10))
+	if got, err := m.Canonical(DefaultAlphabet[:1] + s); err != nil || got != want {
+		t.Fatalf("leading zero: %q %v, want %q", got, err, want)
+	}
+
+	if _, err := New(WithCanonicalVariant(32)); err == nil {
+		t.Fatal("expected out-of-range canonical variant error")
+	}
+	if _, err := New(WithCanonicalVariant(-1)); err == nil {
+		t.Fatal("expected negative canonical variant error")
+	}
+}
+
+// TestCanonicalTagged 设置 tagBits 时 Canonical 保留 tag，只固定随机部分。
+func TestCanonicalTagged(t *testing.T) {
+	idx, _ := NewIdx(WithMaxVariants(32), WithTagBits(2))
+	m, err := New(WithIdx(idx), WithCanonicalVariant(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for tag := 0; tag <= idx.MaxTag(); tag++ {
+		want, _ := m.EncodeWithVariant(tag*8+3, uint32(7))
+		for i := 0; i < 32; i++ {
+			s, _ := m.EncodeTagged(tag, uint32(7))
+			got, err := m.Canonical(s)
+			if err != nil || got != want {
+				t.Fatalf("tag %d: %q => %q %v, want %q", tag, s, got, err, want)
+			}
+		}
+		if gotTag, _, _ := m.DecodeTagged(want); gotTag != tag {
+			t.Fatalf("tag %d lost: %d", tag, gotTag)
+		}
+	}
+	if _, err := New(WithIdx(idx), WithCanonicalVariant(8)); err == nil {
+		t.Fatal("canonical variant must be below the tag span")
+	}
+}
+
+// TestEqual 比较解码内容：variant 不同仍相等；值、类型、顺序或 tag 不同即不等；无法解码为不等。
+func TestEqual(t *testing.T) {
+	idx, _ := NewIdx(WithTagBits(1))
+	m, _ := New(WithIdx(idx))
+	a, _ := m.EncodeWithVariant(0, uint32(1001), "eu")
+	b, _ := m.EncodeWithVariant(9, uint32(1001), "eu")
+	if !m.Equal(a, b) || !m.Equal(a, a) {
+		t.Fatalf("%q and %q should be equal", a, b)
+	}
+	for _, other := range [][]any{
+		{uint32(1002), "eu"},
+		{uint16(1001), "eu"},
+		{"eu", uint32(1001)},
+		{uint32(1001)},
+	} {
+		s, _ := m.EncodeWithVariant(0, other...)
+		if m.Equal(a, s) {
+			t.Fatalf("%v should differ from %q", other, a)
+		}
+	}
+	tagged, _ := m.EncodeWithVariant(16, uint32(1001), "eu")
+	if m.Equal(a, tagged) {
+		t.Fatal("different tags should not be equal")
+	}
+	if m.Equal(a, "!!") || m.Equal("!!", "!!") {
+		t.Fatal("undecodable strings should not be equal")
+	}
+}
+
+// TestFingerprint 同一内容的指纹与 variant、Codec、校验方式无关；kind、值不同则指纹不同。
+func TestFingerprint(t *testing.T) {
+	values := []any{uint32(1001), "eu"}
+	radix, _ := New()
+	crc, _ := NewIdx(WithChecksum(ChecksumCRC16), WithVersion(IdxV12))
+	b64, _ := New(WithIdx(crc), WithCodec(NewBase64URLCodec()))
+
+	want := ""
+	for _, m := range []*IdMix{radix, b64} {
+		for v := 0; v < 32; v++ {
+			s, _ := m.EncodeWithVariant(v, values...)
+			fp, err := m.Fingerprint(s)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want == "" {
+				want = fp
+			}
+			if fp != want {
+				t.Fatalf("%q => %s, want %s", s, fp, want)
+			}
+		}
+	}
+	if len(want) != 32 {
+		t.Fatalf("fingerprint length %d", len(want))
+	}
+	t.Logf("fingerprint(%v) = %s", values, want)
+
+	// 固定向量：指纹格式变化会让已落库的键失效
+	if want != "fcd6a2e0deeb5c138acaf05d45693e10" {
+		t.Fatalf("fingerprint vector changed: %s", want)
+	}
+
+	other := mustEncode(t, radix, uint32(1002), "eu")
+	if fp, _ := radix.Fingerprint(other); fp == want {
+		t.Fatal("different values should not share a fingerprint")
+	}
+	users := radix.ForKind("user")
+	userToken := mustEncode(t, users, values...)
+	if fp, _ := users.Fingerprint(userToken); fp == want {
+		t.Fatal("kind should change the fingerprint")
+	}
+	rejected := 0
+	for v := 0; v < 32; v++ {
+		s, _ := radix.EncodeWithVariant(v, values...)
+		if _, err := users.Fingerprint(s); err != nil {
+			if !errors.Is(err, ErrWrongKind) {
+				t.Fatalf("kind mismatch: %v", err)
+			}
+			rejected++
+		}
+	}
+	if rejected == 0 {
+		t.Fatal("no token was rejected as another kind")
+	}
+	if _, err := radix.Fingerprint("!!"); err == nil {
+		t.Fatal("expected decode error")
+	}
+}
@@ -0,0 +1,235 @@
+// checksum.go 实现可选的强校验：在扩展头中存放完整的 CRC-8 或 CRC-16。
+//
+// 默认 header 只有 1~2 位 XOR 校验，随机错误约 25% 被误接受。启用 WithChecksum 后，
+// 二进制块改用扩展头（标记方式见 arithmetic.md 4.2 节）：
+//
+//	[header(bit7=1)] [0x00] [ext] [version?] [count?] [crc 1~2 字节] + [数据对象序列]
+//
+// 多对象 header 的 count 从不为 0，旧解码器遇到 0x00 会直接报 invalid count，
+// 新解码器据此识别扩展头。ext 字节：
+//
+//	bit7-6 = 保留（须为 0）
+//	bit5   = 块绑定了 kind，末尾带 kind 校验字节（见 kind.go）
+//	bit4   = 对象序列末尾两个对象为有效期元数据（见 ttl.go）
+//	bit3   = 其后是否有版本字节（见 version.go）
+//	bit2   = 其后是否有 count 字节（单对象时省略）
+//	bit1-0 = 校验类型（0=仅 XOR，须带版本字节、有效期或 kind 标记；1=CRC-8, 2=CRC-16）
+//
+// CRC 覆盖 check 位清零的头部（不含 CRC 字段）与**未混淆**的对象序列，
+// 因此在不同 kind 间同样有效；header 原有的 check 位照常计算，覆盖整个块。
+package idmix
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ChecksumMode 为 IDX 块的校验方式。
+type ChecksumMode uint8
+
+const (
+	// ChecksumXOR 为默认方式：header 中 1~2 位 XOR 校验，无额外字节。
+	ChecksumXOR ChecksumMode = iota
+	// ChecksumCRC8 在扩展头中存放 CRC-8/SMBUS（poly 0x07, init 0x00），额外 3 字节。
+	ChecksumCRC8
+	// ChecksumCRC16 在扩展头中存放 CRC-16/IBM-3740（poly 0x1021, init 0xFFFF，大端），额外 4 字节。
+	ChecksumCRC16
+)
+
+const (
+	extMarker     = 0x00 // 扩展头标记，占用多对象 header 的 count 位置
+	extHasKind    = 0x20 // 块绑定了 kind，末尾带 kind 校验字节（见 kind.go）
+	extHasTTL     = 0x10 // 末尾两个对象为有效期元数据（见 ttl.go）
+	extHasVersion = 0x08 // 其后有版本字节（见 version.go）
+	extHasCount   = 0x04
+	extModeMask   = 0x03
+	extReserved   = 0xC0
+)
+
+// ErrChecksumMismatch header 的 check 位或扩展头的 CRC 与内容不符（可用 errors.Is 判断）。
+var ErrChecksumMismatch = errors.New("checksum mismatch")
+
+func (c ChecksumMode) String() string {
+	switch c {
+	case ChecksumXOR:
+		return "xor"
+	case ChecksumCRC8:
+		return "crc8"
+	case ChecksumCRC16:
+		return "crc16"
+	}
+	return fmt.Sprintf("ChecksumMode(%d)", uint8(c))
+}
+
+// ParseChecksumMode 按名称（xor、crc8、crc16）解析校验方式。
+func ParseChecksumMode(name string) (ChecksumMode, error) {
+	for _, c := range []ChecksumMode{ChecksumXOR, ChecksumCRC8, ChecksumCRC16} {
+		if c.String() == name {
+			return c, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown checksum mode %q", name)
+}
+
+// crcLen 返回 CRC 字段的字节数。
+func (c ChecksumMode) crcLen() int {
+	switch c {
+	case ChecksumCRC8:
+		return 1
+	case ChecksumCRC16:
+		return 2
+	}
+	return 0
+}
+
+// WithChecksum 设置编码时使用的校验方式（默认 ChecksumXOR）。
+//
+// 设置为 CRC 时，解码也要求块中的 CRC 不弱于该方式；默认配置的 Idx 可解码任意方式的块。
+func WithChecksum(mode ChecksumMode) IdxOption {
+	return func(idx *Idx) error {
+		if mode > ChecksumCRC16 {
+			return fmt.Errorf("unknown checksum mode %d", mode)
+		}
+		idx.checksumMode = mode
+		return nil
+	}
+}
+
+// ChecksumMode 返回编码时使用的校验方式。
+func (idx *Idx) ChecksumMode() ChecksumMode { return idx.checksumMode }
+
+// appendExtendedHeader 将扩展头（check 位为 0）追加到 dst 后返回，plain 为未混淆的对象序列。
+func (idx *Idx) appendExtendedHeader(dst []byte, variantID, count int, plain []byte, ttl bool) []byte {
+	mode := idx.checksumMode
+	start := len(dst)
+	header := append(dst, 0x80|byte(variantID<<idx.checkBits), extMarker, byte(mode))
+	if ttl {
+		header[start+2] |= extHasTTL
+	}
+	if idx.kindSalt != nil {
+		header[start+2] |= extHasKind
+	}
+	if idx.version != IdxVersionUnmarked {
+		header[start+2] |= extHasVersion
+		header = append(header, byte(idx.version))
+	}
+	if count > 1 {
+		header[start+2] |= extHasCount
+		header = append(header, byte(count))
+	}
+	switch mode {
+	case ChecksumCRC8:
+		return append(header, byte(crcOf(mode, header[start:], plain)))
+	case ChecksumCRC16:
+		sum := crcOf(mode, header[start:], plain)
+		return append(header, byte(sum>>8), byte(sum))
+	}
+	return header
+}
+
+// parseExtendedHeader 解析 data[1] == extMarker 的扩展头，在 h 上补充校验方式、有效期与 kind 标记、版本、对象个数与头部长度。
+func (idx *Idx) parseExtendedHeader(data []byte, h idxHeader) (idxHeader, error) {
+	if len(data) < 3 {
+		return idxHeader{}, errors.New("invalid data: missing extension byte")
+	}
+	ext := data[2]
+	if ext&extReserved != 0 {
+		return idxHeader{}, fmt.Errorf("invalid extension byte %#02x: reserved bits set", ext)
+	}
+	h.mode = ChecksumMode(ext & extModeMask)
+	h.ttl = ext&extHasTTL != 0
+	h.kind = ext&extHasKind != 0
+	if h.mode > ChecksumCRC16 || (h.mode == ChecksumXOR && ext&(extHasVersion|extHasTTL|extHasKind) == 0) {
+		return idxHeader{}, fmt.Errorf("invalid extension byte %#02x: unknown checksum mode", ext)
+	}
+	h.headerLen = 3
+	if ext&extHasVersion != 0 {
+		if len(data) < 4 {
+			return idxHeader{}, errors.New("invalid data: missing version byte")
+		}
+		h.version = IdxVersion(data[3])
+		if _, ok := idxParsers[h.version]; !ok {
+			return idxHeader{}, &UnsupportedVersionError{Version: h.version}
+		}
+		h.headerLen++
+	}
+	if ext&extHasCount != 0 {
+		if len(data) < h.headerLen+1 {
+			return idxHeader{}, errors.New("invalid data: missing count byte")
+		}
+		h.count = int(data[h.headerLen])
+		h.headerLen++
+		if h.count < 2 || h.count > idx.maxObjects {
+			return idxHeader{}, fmt.Errorf("invalid count %d", h.count)
+		}
+		if err := idx.limits.checkCount(h.count); err != nil {
+			return idxHeader{}, err
+		}
+	}
+	h.headerLen += h.mode.crcLen()
+	if len(data) < h.headerLen {
+		return idxHeader{}, errors.New("invalid data: missing crc")
+	}
+	return h, nil
+}
+
+// verifyCRC 校验扩展头中的 CRC，plain 为还原后的对象序列。
+func (idx *Idx) verifyCRC(data []byte, mode ChecksumMode, headerLen int, plain []byte) error {
+	n := headerLen - mode.crcLen()
+	header := make([]byte, n)
+	copy(header, data[:n])
+	header[0] &^= idx.checkMask
+	var stored uint16
+	for _, b := range data[n:headerLen] {
+		stored = stored<<8 | uint16(b)
+	}
+	if crcOf(mode, header, plain) != stored {
+		return ErrChecksumMismatch
+	}
+	return nil
+}
+
+func crcOf(mode ChecksumMode, parts ...[]byte) uint16 {
+	if mode == ChecksumCRC16 {
+		crc := uint16(0xFFFF)
+		for _, p := range parts {
+			crc = crc16(crc, p)
+		}
+		return crc
+	}
+	crc := byte(0)
+	for _, p := range parts {
+		crc = crc8(crc, p)
+	}
+	return uint16(crc)
+}
+
+// crc8 为 CRC-8/SMBUS（poly 0x07，不反射，无输出异或）。
+func crc8(crc byte, data []byte) byte {
+	for _, b := range data {
+		crc ^= b
+		for i := 0; i < 8; i++ {
+			if crc&0x80 != 0 {
+				crc = crc<<1 ^ 0x07
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
+
+// crc16 为 CRC-16/IBM-3740（poly 0x1021，不反射，无输出异或）。
+func crc16(crc uint16, data []byte) uint16 {
+	for _, b := range data {
+		crc ^= uint16(b) << 8
+		for i := 0; i < 8; i++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
@@ -0,0 +1,168 @@
+// checksum_test.go 覆盖 CRC-8 / CRC-16 扩展头：固定向量、兼容性与误接受率统计。
+package idmix
+
+import (
+	"encoding/hex"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+// TestCRCCheckValues 以标准校验值确认 CRC 参数（"123456789"）。
+func TestCRCCheckValues(t *testing.T) {
+	if got := crc8(0, []byte("123456789")); got != 0xF4 {
+		t.Fatalf("CRC-8/SMBUS check = %#02x, want 0xf4", got)
+	}
+	if got := crc16(0xFFFF, []byte("123456789")); got != 0x29B1 {
+		t.Fatalf("CRC-16/IBM-3740 check = %#04x, want 0x29b1", got)
+	}
+}
+
+// TestChecksumVectors 固定向量（二进制与默认字符表文本），供其他语言实现比对。
+func TestChecksumVectors(t *testing.T) {
+	cases := []struct {
+		mode    ChecksumMode
+		variant int
+		values  []any
+		hex     string
+		encoded string
+	}{
+		{ChecksumCRC8, 0, []any{uint8(10)}, "820001123d", "bSMr90Nt"},
+		{ChecksumCRC8, 7, []any{uint8(10)}, "9c00019d88", "bUKlsw8i"},
+		{ChecksumCRC8, 0, []any{uint16(5), int64(-1), uint32(40)}, "830005039e2247b51f", "n5moU6uZRPtWR"},
+		{ChecksumCRC8, 0, []any{"hello", uint16(5), "世界"}, "8200050318f25f525b5b5822f1d38fa1d0a2bb", "cWJK6ueTvUL3TBQtOFRfrYqrDnP"},
+		{ChecksumCRC16, 0, []any{uint8(10)}, "8100029ed03d", "ix0qSmYoX"},
+		{ChecksumCRC16, 7, []any{uint8(10)}, "9d0002ca4588", "iGKrw1B76"},
+		{ChecksumCRC16, 0, []any{uint16(5), int64(-1), uint32(40)}, "8000060308f62247b51f", "bbCEGkB8SVFP8j5"},
+		{ChecksumCRC16, 7, []any{"hello", uint16(5), "世界"}, "9d000603e8c847eae7eeeeed9744663a1465170e", "mi6FPcvXuJOVGjcPnkhBFBZFQvUw"},
+	}
+	for _, c := range cases {
+		idx, err := NewIdx(WithChecksum(c.mode))
+		if err != nil {
+			t.Fatal(err)
+		}
+		m, err := New(WithIdx(idx))
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := idx.EncodeWithVariant(c.variant, c.values...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := hex.EncodeToString(data); got != c.hex {
+			t.Fatalf("%s v=%d %v: binary %s, want %s", c.mode, c.variant, c.values, got, c.hex)
+		}
+		s, err := m.EncodeWithVariant(c.variant, c.values...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s != c.encoded {
+			t.Fatalf("%s v=%d %v: encoded %s, want %s", c.mode, c.variant, c.values, s, c.encoded)
+		}
+		list, err := m.Decode(s)
+		if err != nil || !reflect.DeepEqual(list, c.values) {
+			t.Fatalf("%s: decode %v %v", c.mode, list, err)
+		}
+		t.Logf("%s v=%d %v => %s => %s", c.mode, c.variant, c.values, formatHex(data), s)
+	}
+}
+
+func TestChecksumCompatibility(t *testing.T) {
+	plain, _ := NewIdx()
+	crc8Idx, _ := NewIdx(WithChecksum(ChecksumCRC8))
+	crc16Idx, _ := NewIdx(WithChecksum(ChecksumCRC16))
+
+	xorBlock, _ := plain.EncodeWithVariant(3, uint32(1001))
+	crc8Block, _ := crc8Idx.EncodeWithVariant(3, uint32(1001))
+	crc16Block, _ := crc16Idx.EncodeWithVariant(3, uint32(1001))
+
+	// 默认 Idx 可解码任意方式；CRC Idx 拒绝更弱的方式
+	for _, b := range [][]byte{xorBlock, crc8Block, crc16Block} {
+		if _, err := plain.Decode(b); err != nil {
+			t.Fatalf("default idx %x: %v", b, err)
+		}
+	}
+	if _, err := crc8Idx.Decode(crc16Block); err != nil {
+		t.Fatalf("crc8 idx should accept crc16: %v", err)
+	}
+	for _, c := range []struct {
+		idx  *Idx
+		data []byte
+	}{{crc8Idx, xorBlock}, {crc16Idx, xorBlock}, {crc16Idx, crc8Block}} {
+		if _, err := c.idx.Decode(c.data); err == nil {
+			t.Fatalf("%s idx accepted %x", c.idx.ChecksumMode(), c.data)
+		} else {
+			t.Logf("%s idx, %x => %v", c.idx.ChecksumMode(), c.data, err)
+		}
+	}
+
+	// 保留位或未知校验类型
+	for _, ext := range []byte{0x08, 0x80, 0x00, 0x03} {
+		bad := append([]byte{}, crc8Block...)
+		bad[2] = ext
+		if _, err := plain.Decode(bad); err == nil {
+			t.Fatalf("ext %#02x accepted", ext)
+		}
+	}
+	// 截断的扩展头
+	for n := 2; n < 4; n++ {
+		if _, err := plain.Decode(crc16Block[:n]); err == nil {
+			t.Fatalf("truncated %x accepted", crc16Block[:n])
+		}
+	}
+
+	if _, err := NewIdx(WithChecksum(ChecksumMode(3))); err == nil {
+		t.Fatal("expected unknown mode error")
+	}
+	for _, name := range []string{"xor", "crc8", "crc16"} {
+		if m, err := ParseChecksumMode(name); err != nil || m.String() != name {
+			t.Fatalf("%s: %v %v", name, m, err)
+		}
+	}
+}
+
+// TestChecksumFalseAccept 统计文本层随机替换 1~2 个字符后仍被成功解码（且值被改变）的比例。
+func TestChecksumFalseAccept(t *testing.T) {
+	const n = 30000
+	alphabet := []rune(DefaultAlphabet)
+	cases := []struct {
+		mode ChecksumMode
+		max  [2]float64 // 单字符、双字符替换的误接受率上限
+	}{
+		{ChecksumXOR, [2]float64{0.1, 0.05}},
+		{ChecksumCRC8, [2]float64{0.005, 0.005}},
+		{ChecksumCRC16, [2]float64{0.0005, 0.0005}},
+	}
+	for _, c := range cases {
+		idx, _ := NewIdx(WithChecksum(c.mode))
+		m, _ := New(WithIdx(idx))
+		r := rand.New(rand.NewSource(7))
+		for typos := 1; typos <= 2; typos++ {
+			accepted := 0
+			for i := 0; i < n; i++ {
+				vals := []any{r.Uint32(), uint16(r.Intn(1 << 16))}
+				s, err := m.Encode(vals...)
+				if err != nil {
+					t.Fatal(err)
+				}
+				runes := []rune(s)
+				for k := 0; k < typos; k++ {
+					// 跳过 2 字节长度前缀所在的首字符，避免仅因长度不符被拒
+					pos := 1 + r.Intn(len(runes)-1)
+					orig := runes[pos]
+					for runes[pos] == orig {
+						runes[pos] = alphabet[r.Intn(len(alphabet))]
+					}
+				}
+				if list, err := m.Decode(string(runes)); err == nil && !reflect.DeepEqual(list, vals) {
+					accepted++
+				}
+			}
+			rate := float64(accepted) / n
+			t.Logf("%-5s %d typo(s): false accept %d/%d (%.4f%%)", c.mode, typos, accepted, n, rate*100)
+			if rate > c.max[typos-1] {
+				t.Fatalf("%s: false accept rate %.5f > %.5f", c.mode, rate, c.max[typos-1])
+			}
+		}
+	}
+}
@@ -0,0 +1,117 @@
+// chunked.go 实现 RadixCodec 的分块模式：每 8 字节输入独立转换为定长字符组，
+// 与 Base58Check / Base85 的分组思路相同，时间与载荷长度成线性关系，无长度上限。
+//
+// 设字符表长度为 N（2~256），D_r 为满足 N^D_r ≥ 256^r 的最小整数：
+//
+//	完整块：8 字节（大端 uint64）→ D_8 个字符（高位在前，不足补 alphabet[0]）
+//	末尾块：r 字节（1~7）→ D_r 个字符
+//
+// N ≤ 256 时 D_r 随 r 严格递增，解码由末组字符数唯一确定 r，无需长度前缀；
+// 组值超出 256^r 的输入视为非法。
+package idmix
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"math/bits"
+)
+
+const chunkBytes = 8
+
+// NewChunkedRadixCodec 创建分块模式的 RadixCodec，适合编码 KB~MB 级载荷；
+// 字符表长度须为 2~256。输出与 NewRadixCodec 不兼容，短 ID 通常比标准模式略长。
+func NewChunkedRadixCodec(alphabet string, checks ...AlphabetCheck) (*RadixCodec, error) {
+	rc, err := NewRadixCodec(alphabet, checks...)
+	if err != nil {
+		return nil, err
+	}
+	if rc.base > 256 {
+		return nil, fmt.Errorf("chunked radix alphabet must have at most 256 characters, got %d", rc.base)
+	}
+	rc.mode = radixChunked
+	rc.chunkDigits = make([]int, chunkBytes+1)
+	p := big.NewInt(1)
+	base := big.NewInt(int64(rc.base))
+	d := 0
+	for r := 1; r <= chunkBytes; r++ {
+		limit := new(big.Int).Lsh(big.NewInt(1), uint(8*r))
+		for p.Cmp(limit) < 0 {
+			p.Mul(p, base)
+			d++
+		}
+		rc.chunkDigits[r] = d
+	}
+	return rc, nil
+}
+
+// Chunked 报告是否为分块模式。
+func (rc *RadixCodec) Chunked() bool {
+	return rc.mode == radixChunked
+}
+
+func (rc *RadixCodec) encodeChunked(data []byte) string {
+	full := len(data) / chunkBytes
+	rest := len(data) % chunkBytes
+	out := make([]rune, full*rc.chunkDigits[chunkBytes]+rc.chunkDigits[rest])
+	pos := 0
+	for off := 0; off < len(data); off += chunkBytes {
+		block := data[off:min(off+chunkBytes, len(data))]
+		var v uint64
+		for _, b := range block {
+			v = v<<8 | uint64(b)
+		}
+		d := rc.chunkDigits[len(block)]
+		for i := d - 1; i >= 0; i-- {
+			out[pos+i] = rc.chars[v%uint64(rc.base)]
+			v /= uint64(rc.base)
+		}
+		pos += d
+	}
+	return string(out)
+}
+
+func (rc *RadixCodec) decodeChunked(s string) ([]byte, error) {
+	runes := []rune(s)
+	group := rc.chunkDigits[chunkBytes]
+	full := len(runes) / group
+	rest := 0
+	if tail := len(runes) % group; tail != 0 {
+		for r := 1; r < chunkBytes; r++ {
+			if rc.chunkDigits[r] == tail {
+				rest = r
+			}
+		}
+		if rest == 0 {
+			return nil, fmt.Errorf("invalid chunked length %d", len(runes))
+		}
+	}
+	out := make([]byte, 0, full*chunkBytes+rest)
+	for pos := 0; pos < len(runes); {
+		n, d := chunkBytes, group
+		if pos+group > len(runes) {
+			n, d = rest, len(runes)-pos
+		}
+		var v uint64
+		for _, r := range runes[pos : pos+d] {
+			idx, ok := rc.fromCustom[r]
+			if !ok {
+				return nil, fmt.Errorf("invalid character %q", r)
+			}
+			hi, lo := bits.Mul64(v, uint64(rc.base))
+			sum, carry := bits.Add64(lo, uint64(idx), 0)
+			if hi != 0 || carry != 0 {
+				return nil, errors.New("chunk value overflows 8 bytes")
+			}
+			v = sum
+		}
+		if n < chunkBytes && v>>(8*n) != 0 {
+			return nil, fmt.Errorf("chunk value overflows %d bytes", n)
+		}
+		for i := n - 1; i >= 0; i-- {
+			out = append(out, byte(v>>(8*i)))
+		}
+		pos += d
+	}
+	return out, nil
+}
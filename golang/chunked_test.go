@@ -0,0 +1,163 @@
+// chunked_test.go 覆盖分块 RadixCodec：固定向量、任意长度往返、非法输入，以及 1KB~1MB 基准。
+package idmix
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+// TestChunkedRadixVectors 固定向量（默认 62 字符表：D_1..D_8 = 2,3,5,6,7,9,10,11）。
+func TestChunkedRadixVectors(t *testing.T) {
+	rc, err := NewChunkedRadixCodec(DefaultAlphabet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := fmt.Sprint(rc.chunkDigits[1:]); got != "[2 3 5 6 7 9 10 11]" {
+		t.Fatalf("chunk digits %s", got)
+	}
+	cases := []struct {
+		hex     string
+		encoded string
+	}{
+		{"", ""},
+		{"00", "aa"},
+		{"0000", "aaa"},
+		{"ff", "eh"},
+		{"0102030405060708", "afwMtWnQ15M"},
+		{"ffffffffffffffff", "v8QrKbgkrIp"},
+		{"000102030405060708090a0b0c0d0e0f10", "aabsIDaHvGBaQV0O6RVGV1aq"},
+		{"68656c6c6f20776f726c64", "i7RsZx9vVN5aFCXo"},
+	}
+	for _, c := range cases {
+		data, _ := hex.DecodeString(c.hex)
+		s, err := rc.Encode(data)
+		if err != nil || s != c.encoded {
+			t.Fatalf("%s: got %q %v, want %q", c.hex, s, err, c.encoded)
+		}
+		back, err := rc.Decode(s)
+		if err != nil || !bytes.Equal(back, data) {
+			t.Fatalf("%q: got %x %v", s, back, err)
+		}
+		t.Logf("%-36s => %q", c.hex, s)
+	}
+	hex16, _ := NewChunkedRadixCodec("0123456789abcdef")
+	if s, _ := hex16.Encode([]byte{0xDE, 0xAD, 0xBE, 0xEF}); s != "deadbeef" {
+		t.Fatalf("base16 chunked %q", s)
+	}
+}
+
+func TestChunkedRadixRoundTrip(t *testing.T) {
+	r := rand.New(rand.NewSource(10))
+	for _, alphabet := range []string{"01", AlphabetCrockford32, DefaultAlphabet, AlphabetURLSafe64} {
+		rc, err := NewChunkedRadixCodec(alphabet)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for n := 0; n <= 70; n++ {
+			data := make([]byte, n)
+			r.Read(data)
+			s, _ := rc.Encode(data)
+			back, err := rc.Decode(s)
+			if err != nil || !bytes.Equal(back, data) {
+				t.Fatalf("base %d n=%d: %v", rc.Base(), n, err)
+			}
+		}
+	}
+
+	// 超过标准模式 65535 字节上限的载荷
+	rc, _ := NewChunkedRadixCodec(DefaultAlphabet)
+	big := make([]byte, 1<<20+3)
+	r.Read(big)
+	s, err := EncodeBytes(big, rc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	back, err := DecodeString(s, rc)
+	if err != nil || !bytes.Equal(back, big) {
+		t.Fatalf("1MB round trip: %v", err)
+	}
+	t.Logf("1 MiB + 3 bytes => %d chars (%.3f chars/byte)", len(s), float64(len(s))/float64(len(big)))
+
+	_, err = EncodeBytes(make([]byte, 65536))
+	if !errors.Is(err, ErrPayloadTooLarge) {
+		t.Fatalf("standard radix 64KiB: %v", err)
+	}
+	t.Logf("standard radix 65536 bytes => %v", err)
+	if _, err := EncodeBytes(make([]byte, 65535)); err != nil {
+		t.Fatalf("standard radix 65535 bytes: %v", err)
+	}
+}
+
+func TestChunkedRadixInvalid(t *testing.T) {
+	rc, _ := NewChunkedRadixCodec(DefaultAlphabet)
+	cases := map[string]string{
+		"bad_length":     "a",            // 1 个字符不对应任何末尾块
+		"bad_tail":       "aaaaaaaaaaaa", // 11 + 1
+		"full_overflow":  "99999999999",  // 62^11 − 1 > 2^64
+		"tail_overflow":  "99",           // 62^2 − 1 > 255
+		"bad_character":  "a!",
+		"bad_in_middle":  "aaaaa!aaaaa",
+		"tail_overflow2": "afwMtWnQ15M99",
+	}
+	for name, s := range cases {
+		if _, err := rc.Decode(s); err == nil {
+			t.Fatalf("%s: %q accepted", name, s)
+		} else {
+			t.Logf("%s: %q => %v", name, s, err)
+		}
+	}
+	if _, err := NewChunkedRadixCodec(""); err == nil {
+		t.Fatal("expected empty alphabet error")
+	}
+	wide := make([]rune, 300)
+	for i := range wide {
+		wide[i] = rune(0x4E00 + i)
+	}
+	if _, err := NewChunkedRadixCodec(string(wide)); err == nil {
+		t.Fatal("expected alphabet size error")
+	}
+	secret, err := New(WithCodec(rc), WithSecretAlphabet([]byte("k")))
+	if err != nil || !secret.Codec().(*RadixCodec).Chunked() {
+		t.Fatalf("WithSecretAlphabet should keep chunked mode: %v", err)
+	}
+}
+
+// BenchmarkRadixPayload 对比整体进制转换与分块模式在 1KB~1MB 载荷上的编解码耗时。
+// 标准模式受 65535 字节上限限制，仅测到 64KB 以下。
+func BenchmarkRadixPayload(b *testing.B) {
+	standard, _ := NewRadixCodec(DefaultAlphabet)
+	chunked, _ := NewChunkedRadixCodec(DefaultAlphabet)
+	for _, size := range []int{1 << 10, 16 << 10, 60 << 10, 256 << 10, 1 << 20} {
+		data := make([]byte, size)
+		rand.New(rand.NewSource(1)).Read(data)
+		for _, c := range []struct {
+			name  string
+			codec *RadixCodec
+		}{{"standard", standard}, {"chunked", chunked}} {
+			if c.codec == standard && size > maxRadixPayload {
+				continue
+			}
+			s, err := c.codec.Encode(data)
+			if err != nil {
+				b.Fatal(err)
+			}
+			name := fmt.Sprintf("%s/%dKB", c.name, size>>10)
+			b.Run(name+"/encode", func(b *testing.B) {
+				b.SetBytes(int64(size))
+				for i := 0; i < b.N; i++ {
+					_, _ = c.codec.Encode(data)
+				}
+			})
+			b.Run(name+"/decode", func(b *testing.B) {
+				b.SetBytes(int64(size))
+				for i := 0; i < b.N; i++ {
+					_, _ = c.codec.Decode(s)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,205 @@
+// handler.go 实现 /encode、/decode、/inspect、/healthz 的 JSON 接口。
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	idmix "github.com/Vanni-Fan/idmix/golang"
+)
+
+// maxRequestBody 限制请求体大小（255 个 63 字节字符串的 JSON 远小于此值）。
+const maxRequestBody = 1 << 20
+
+type encodeRequest struct {
+	Profile string             `json:"profile"`
+	Values  []idmix.TypedValue `json:"values"`
+	// Variant 指定 variant_id 以获得确定性输出；缺省时随机。
+	Variant *int `json:"variant,omitempty"`
+}
+
+type encodeResponse struct {
+	Encoded string `json:"encoded"`
+}
+
+type decodeRequest struct {
+	Profile string `json:"profile"`
+	Encoded string `json:"encoded"`
+}
+
+type decodeResponse struct {
+	Values []idmix.TypedValue `json:"values"`
+}
+
+type inspectResponse struct {
+	Values  []idmix.TypedValue `json:"values"`
+	Variant int                `json:"variant"`
+	// Version 为块的 IDX 格式版本（如 "v1.2"），VersionMarked 报告块中是否带版本标记。
+	Version       string `json:"version"`
+	VersionMarked bool   `json:"versionMarked"`
+	Binary        string `json:"binary"`
+	Length        int    `json:"length"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// httpError 携带状态码的处理错误。
+type httpError struct {
+	status int
+	err    error
+}
+
+func (e *httpError) Error() string { return e.err.Error() }
+
+func badRequest(err error) error { return &httpError{status: http.StatusBadRequest, err: err} }
+
+func newHandler(profiles *idmix.ProfileRegistry) http.Handler {
+	s := &server{profiles: profiles}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /encode", s.handle(s.encode))
+	mux.HandleFunc("POST /decode", s.handle(s.decode))
+	mux.HandleFunc("POST /inspect", s.handle(s.inspect))
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	})
+	return mux
+}
+
+type server struct {
+	profiles *idmix.ProfileRegistry
+}
+
+func (s *server) handle(fn func(r *http.Request) (any, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		resp, err := fn(r)
+		if err != nil {
+			status := http.StatusInternalServerError
+			var he *httpError
+			if errors.As(err, &he) {
+				status = he.status
+			}
+			writeJSON(w, status, errorResponse{Error: err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, resp)
+	}
+}
+
+func (s *server) profile(name string) (*idmix.IdMix, error) {
+	if name == "" {
+		name = defaultProfile
+	}
+	m, err := s.profiles.Get(name)
+	if err != nil {
+		return nil, &httpError{status: http.StatusNotFound, err: err}
+	}
+	return m, nil
+}
+
+func (s *server) encode(r *http.Request) (any, error) {
+	var req encodeRequest
+	if err := readJSON(r, &req); err != nil {
+		return nil, err
+	}
+	m, err := s.profile(req.Profile)
+	if err != nil {
+		return nil, err
+	}
+	values := make([]any, len(req.Values))
+	for i, tv := range req.Values {
+		v, err := tv.Value()
+		if err != nil {
+			return nil, badRequest(fmt.Errorf("values[%d]: %w", i, err))
+		}
+		values[i] = v
+	}
+	var enc string
+	if req.Variant != nil {
+		enc, err = m.EncodeWithVariant(*req.Variant, values...)
+	} else {
+		enc, err = m.Encode(values...)
+	}
+	if err != nil {
+		return nil, badRequest(err)
+	}
+	return encodeResponse{Encoded: enc}, nil
+}
+
+func (s *server) decode(r *http.Request) (any, error) {
+	info, err := s.inspectRequest(r)
+	if err != nil {
+		return nil, err
+	}
+	values, err := typedValues(info.Values)
+	if err != nil {
+		return nil, err
+	}
+	return decodeResponse{Values: values}, nil
+}
+
+func (s *server) inspect(r *http.Request) (any, error) {
+	info, err := s.inspectRequest(r)
+	if err != nil {
+		return nil, err
+	}
+	values, err := typedValues(info.Values)
+	if err != nil {
+		return nil, err
+	}
+	return inspectResponse{
+		Values:        values,
+		Variant:       info.VariantID,
+		Version:       info.Version.String(),
+		VersionMarked: info.VersionMarked,
+		Binary:        hex.EncodeToString(info.Binary),
+		Length:        len(info.Binary),
+	}, nil
+}
+
+func (s *server) inspectRequest(r *http.Request) (*idmix.Inspection, error) {
+	var req decodeRequest
+	if err := readJSON(r, &req); err != nil {
+		return nil, err
+	}
+	m, err := s.profile(req.Profile)
+	if err != nil {
+		return nil, err
+	}
+	info, err := m.Inspect(req.Encoded)
+	if err != nil {
+		return nil, badRequest(err)
+	}
+	return info, nil
+}
+
+func typedValues(values []any) ([]idmix.TypedValue, error) {
+	out := make([]idmix.TypedValue, len(values))
+	for i, v := range values {
+		tv, err := idmix.NewTypedValue(v)
+		if err != nil {
+			return nil, err
+		}
+		out[i] = tv
+	}
+	return out, nil
+}
+
+func readJSON(r *http.Request, dst any) error {
+	dec := json.NewDecoder(http.MaxBytesReader(nil, r.Body, maxRequestBody))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(dst); err != nil {
+		return badRequest(fmt.Errorf("invalid JSON body: %w", err))
+	}
+	return nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,189 @@
+// handler_test.go 用跨语言向量校验 HTTP 接口输出与 Go 参考实现一致。
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	idmix "github.com/Vanni-Fan/idmix/golang"
+)
+
+type vectorFile struct {
+	Alphabet string `json:"alphabet"`
+	Cases    []struct {
+		Name    string             `json:"name"`
+		Variant int                `json:"variant"`
+		Values  []idmix.TypedValue `json:"values"`
+		Encoded string             `json:"encoded"`
+	} `json:"cases"`
+}
+
+func loadVectors(t *testing.T) vectorFile {
+	t.Helper()
+	b, err := os.ReadFile(filepath.Join("..", "..", "..", "testdata", "cross_language_vectors.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var f vectorFile
+	if err := json.Unmarshal(b, &f); err != nil {
+		t.Fatal(err)
+	}
+	// 向量文件的字符串项带占位的 "otype": 0，接口要求字符串不带 otype
+	for _, c := range f.Cases {
+		for i := range c.Values {
+			if c.Values[i].Str != "" {
+				c.Values[i].OType = nil
+			}
+		}
+	}
+	return f
+}
+
+func post(t *testing.T, h http.Handler, path string, body any, out any) int {
+	t.Helper()
+	b, err := json.Marshal(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, path, bytes.NewReader(b)))
+	if out != nil {
+		if err := json.Unmarshal(rec.Body.Bytes(), out); err != nil {
+			t.Fatalf("%s: %v (%q)", path, err, rec.Body.String())
+		}
+	}
+	return rec.Code
+}
+
+func TestVectorsOverHTTP(t *testing.T) {
+	f := loadVectors(t)
+	profiles, err := loadProfiles("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := newHandler(profiles)
+	for _, c := range f.Cases {
+		t.Run(c.Name, func(t *testing.T) {
+			variant := c.Variant
+			var enc encodeResponse
+			if code := post(t, h, "/encode", encodeRequest{Values: c.Values, Variant: &variant}, &enc); code != http.StatusOK {
+				t.Fatalf("encode status %d", code)
+			}
+			if enc.Encoded != c.Encoded {
+				t.Fatalf("encoded %q, want %q", enc.Encoded, c.Encoded)
+			}
+
+			var dec decodeResponse
+			if code := post(t, h, "/decode", decodeRequest{Encoded: c.Encoded}, &dec); code != http.StatusOK {
+				t.Fatalf("decode status %d", code)
+			}
+			if len(dec.Values) != len(c.Values) {
+				t.Fatalf("decoded %d values, want %d", len(dec.Values), len(c.Values))
+			}
+			for i := range c.Values {
+				if !reflect.DeepEqual(dec.Values[i], c.Values[i]) {
+					t.Fatalf("[%d] got %+v, want %+v", i, dec.Values[i], c.Values[i])
+				}
+			}
+
+			var info inspectResponse
+			if code := post(t, h, "/inspect", decodeRequest{Profile: "default", Encoded: c.Encoded}, &info); code != http.StatusOK {
+				t.Fatalf("inspect status %d", code)
+			}
+			if info.Variant != c.Variant || info.Version != "v1.2" || info.VersionMarked || info.Length*2 != len(info.Binary) {
+				t.Fatalf("unexpected inspect %+v", info)
+			}
+		})
+	}
+}
+
+func TestHandlerErrors(t *testing.T) {
+	profiles, err := loadProfiles("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := newHandler(profiles)
+	cases := []struct {
+		name   string
+		path   string
+		body   any
+		status int
+	}{
+		{"unknown_profile", "/decode", decodeRequest{Profile: "nope", Encoded: "abc"}, http.StatusNotFound},
+		{"bad_encoded", "/decode", decodeRequest{Encoded: "!"}, http.StatusBadRequest},
+		{"bad_otype", "/encode", encodeRequest{Values: []idmix.TypedValue{{OType: otype(9), Val: "1"}}}, http.StatusBadRequest},
+		{"missing_otype", "/encode", map[string]any{"values": []any{map[string]any{"val": "1"}}}, http.StatusBadRequest},
+		{"str_with_otype", "/encode", map[string]any{"values": []any{map[string]any{"otype": 0, "str": "eu"}}}, http.StatusBadRequest},
+		{"str_with_val", "/encode", map[string]any{"values": []any{map[string]any{"val": "1", "str": "eu"}}}, http.StatusBadRequest},
+		{"no_values", "/encode", encodeRequest{}, http.StatusBadRequest},
+		{"unknown_field", "/encode", map[string]any{"vals": 1}, http.StatusBadRequest},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var resp errorResponse
+			if code := post(t, h, c.path, c.body, &resp); code != c.status {
+				t.Fatalf("status %d, want %d", code, c.status)
+			}
+			if resp.Error == "" {
+				t.Fatal("missing error message")
+			}
+			t.Logf("%s => %d %s", c.path, c.status, resp.Error)
+		})
+	}
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("healthz status %d", rec.Code)
+	}
+}
+
+func TestProfilesFromFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "profiles.json")
+	keysPath := filepath.Join(dir, "keys.json")
+	cfg := `{"profiles":{"short":{"alphabet":"0123456789abcdef","checkBits":1,"maxVariants":4,"keyRef":"k1"}}}`
+	if err := os.WriteFile(path, []byte(cfg), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keysPath, []byte(`{"k1":"5a17"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	profiles, err := loadProfiles(path, keysPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := newHandler(profiles)
+	var enc encodeResponse
+	values := []idmix.TypedValue{{OType: otype(2), Val: "1001"}, {Str: "eu"}}
+	if code := post(t, h, "/encode", encodeRequest{Profile: "short", Values: values}, &enc); code != http.StatusOK {
+		t.Fatalf("encode status %d", code)
+	}
+	var dec decodeResponse
+	if code := post(t, h, "/decode", decodeRequest{Profile: "short", Encoded: enc.Encoded}, &dec); code != http.StatusOK {
+		t.Fatalf("decode status %d", code)
+	}
+	if !reflect.DeepEqual(dec.Values, values) {
+		t.Fatalf("got %+v", dec.Values)
+	}
+	t.Logf("profile short: %q", enc.Encoded)
+
+	if err := os.WriteFile(path, []byte(`{"profiles":{"bad":{"checkBits":3}}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	_, err = loadProfiles(path, keysPath)
+	var pe *idmix.ProfileError
+	if !errors.As(err, &pe) || pe.Field != "checkBits" {
+		t.Fatalf("expected checkBits profile error, got %v", err)
+	}
+	t.Logf("invalid profile => %v", err)
+}
+
+func otype(n int) *int { return &n }
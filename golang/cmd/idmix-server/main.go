@@ -0,0 +1,70 @@
+// Command idmix-server 以本地 HTTP/JSON 服务形式提供 idmix 编解码，
+// 供非 Go 服务与 shell 脚本生成与 Go 参考实现完全一致的编码串。
+//
+// 接口（值使用 testdata/cross_language_vectors.json 的 {otype, val} 结构）：
+//
+//	POST /encode   {"profile":"default","values":[{"otype":2,"val":"42"}],"variant":0}
+//	POST /decode   {"profile":"default","encoded":"..."}
+//	POST /inspect  {"profile":"default","encoded":"..."}
+//	GET  /healthz
+//
+// 用法：
+//
+//	idmix-server -addr 127.0.0.1:8080 -config profiles.json -keys keys.json
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	_ "github.com/Vanni-Fan/idmix/golang/idmixnfc"
+)
+
+func main() {
+	addr := flag.String("addr", "127.0.0.1:8080", "listen address")
+	configPath := flag.String("config", "", "profiles JSON file (default: single \"default\" profile)")
+	keysPath := flag.String("keys", "", "JSON file mapping keyRef names to hex keys")
+	shutdownTimeout := flag.Duration("shutdown-timeout", 10*time.Second, "graceful shutdown timeout")
+	flag.Parse()
+
+	profiles, err := loadProfiles(*configPath, *keysPath)
+	if err != nil {
+		log.Fatalf("load profiles: %v", err)
+	}
+
+	srv := &http.Server{
+		Addr:              *addr,
+		Handler:           newHandler(profiles),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	errc := make(chan error, 1)
+	go func() {
+		log.Printf("idmix-server listening on %s (profiles: %v)", *addr, profiles.Names())
+		errc <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errc:
+		if !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal(err)
+		}
+	case <-ctx.Done():
+		log.Print("shutting down")
+		sctx, cancel := context.WithTimeout(context.Background(), *shutdownTimeout)
+		defer cancel()
+		if err := srv.Shutdown(sctx); err != nil {
+			log.Fatalf("shutdown: %v", err)
+		}
+	}
+}
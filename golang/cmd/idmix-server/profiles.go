@@ -0,0 +1,24 @@
+// profiles.go 加载服务端配置：命名 profile 由 idmix.LoadProfilesFile 构造，
+// keyRef 引用的 XOR 密钥从独立的密钥文件读取。
+package main
+
+import idmix "github.com/Vanni-Fan/idmix/golang"
+
+const defaultProfile = "default"
+
+// loadProfiles 读取配置文件（见 idmix.LoadProfilesFile）；path 为空时仅提供使用库默认配置的 "default" profile。
+// keysPath 为 {"ref": "<hex>"} 格式的密钥文件，可为空。
+func loadProfiles(path, keysPath string) (*idmix.ProfileRegistry, error) {
+	if path != "" {
+		return idmix.LoadProfilesFile(path, keysPath)
+	}
+	keys, err := idmix.LoadKeyMapFile(keysPath)
+	if err != nil {
+		return nil, err
+	}
+	r := idmix.NewProfileRegistry(keys.Resolve)
+	if err := r.Register(idmix.Profile{Name: defaultProfile}); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
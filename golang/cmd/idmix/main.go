@@ -0,0 +1,49 @@
+// Command idmix 为 idmix 的命令行工具。
+//
+// 子命令：
+//
+//	migrate  用旧 profile 解码、新 profile 重新编码，批量改写已存储的编码串（见 migrate.go）
+//
+// 用法：
+//
+//	idmix migrate -config profiles.json -from old -to new < tokens.txt > migrated.txt
+//	idmix migrate -config profiles.json -from old -to new -csv -columns 2,5 -header < orders.csv > orders.new.csv
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	_ "github.com/Vanni-Fan/idmix/golang/idmixnfc"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run 按子命令分派，返回进程退出码。
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		usage(stderr)
+		return 2
+	}
+	switch args[0] {
+	case "migrate":
+		return runMigrate(args[1:], stdin, stdout, stderr)
+	case "help", "-h", "-help", "--help":
+		usage(stdout)
+		return 0
+	}
+	fmt.Fprintf(stderr, "idmix: unknown command %q\n", args[0])
+	usage(stderr)
+	return 2
+}
+
+func usage(w io.Writer) {
+	fmt.Fprint(w, `usage: idmix <command> [flags]
+
+commands:
+  migrate   re-encode tokens from one profile to another (run "idmix migrate -h" for flags)
+`)
+}
@@ -0,0 +1,200 @@
+// migrate.go 实现 migrate 子命令：逐行（或逐 CSV 单元格）调用 idmix.Transcode 改写编码串。
+//
+// 输入从 stdin 流式读取、输出写到 stdout，内存占用与输入大小无关。
+// 失败的编码串原样输出（保持行列对齐），并在 stderr 报告行号；有失败时退出码为 1。
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	idmix "github.com/Vanni-Fan/idmix/golang"
+)
+
+// maxLineBytes 为逐行模式下单行的最大字节数。
+const maxLineBytes = 1 << 20
+
+// errFailFast 表示 -fail-fast 时遇到第一个失败即停止。
+var errFailFast = errors.New("stopped at first failure")
+
+type migrator struct {
+	from, to *idmix.IdMix
+	opts     []idmix.TranscodeOption
+	failFast bool
+	report   io.Writer // 失败报告（stderr）
+
+	tokens   int
+	failures int
+}
+
+// transcode 改写一个编码串；失败时报告 where 并返回原串。
+func (m *migrator) transcode(token, where string) (string, error) {
+	m.tokens++
+	out, err := idmix.Transcode(token, m.from, m.to, m.opts...)
+	if err == nil {
+		return out, nil
+	}
+	m.failures++
+	fmt.Fprintf(m.report, "%s: %q: %v\n", where, token, err)
+	if m.failFast {
+		return token, errFailFast
+	}
+	return token, nil
+}
+
+// lines 处理每行一个编码串的输入；空行与行尾（"\n"、"\r\n"，末行无换行时不补）原样保留，行首尾空白不参与转码。
+func (m *migrator) lines(r io.Reader, w io.Writer) error {
+	br := bufio.NewReader(r)
+	bw := bufio.NewWriter(w)
+	for line := 1; ; line++ {
+		text, rerr := readLine(br)
+		if rerr != nil && rerr != io.EOF {
+			bw.Flush()
+			return rerr
+		}
+		if token := strings.TrimSpace(text); token != "" {
+			out, err := m.transcode(token, fmt.Sprintf("line %d", line))
+			text = strings.Replace(text, token, out, 1)
+			if err != nil {
+				bw.Flush()
+				return err
+			}
+		}
+		bw.WriteString(text)
+		if rerr == io.EOF {
+			return bw.Flush()
+		}
+	}
+}
+
+// readLine 读取一行，包含行尾的换行符（末行可无）；超过 maxLineBytes 时返回 bufio.ErrTooLong。
+func readLine(br *bufio.Reader) (string, error) {
+	var buf []byte
+	for {
+		chunk, err := br.ReadSlice('\n')
+		if len(buf)+len(chunk) > maxLineBytes {
+			return "", bufio.ErrTooLong
+		}
+		buf = append(buf, chunk...)
+		if err != bufio.ErrBufferFull {
+			return string(buf), err
+		}
+	}
+}
+
+// csv 处理 CSV 输入，只改写 columns（从 0 起）中的非空单元格；header 为 true 时首行原样输出。
+func (m *migrator) csv(r io.Reader, w io.Writer, columns []int, header bool) error {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cw := csv.NewWriter(w)
+	defer cw.Flush()
+	for row := 0; ; row++ {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if row > 0 || !header {
+			for _, col := range columns {
+				if col >= len(record) || record[col] == "" {
+					continue
+				}
+				line, _ := cr.FieldPos(col)
+				out, err := m.transcode(record[col], fmt.Sprintf("line %d, column %d", line, col+1))
+				record[col] = out
+				if err != nil {
+					return err
+				}
+			}
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// parseColumns 解析以逗号分隔、从 1 起的列号，返回从 0 起的下标。
+func parseColumns(s string) ([]int, error) {
+	var cols []int
+	for _, f := range strings.Split(s, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(f))
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("invalid column %q (columns start at 1)", f)
+		}
+		cols = append(cols, n-1)
+	}
+	return cols, nil
+}
+
+// runMigrate 解析参数并执行迁移，返回进程退出码：0 全部成功，1 有失败，2 参数或输入错误。
+func runMigrate(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	configPath := fs.String("config", "", "profiles JSON file (required)")
+	keysPath := fs.String("keys", "", "JSON file mapping keyRef names to hex keys")
+	fromName := fs.String("from", "", "profile that encoded the existing tokens (required)")
+	toName := fs.String("to", "", "profile to re-encode with (required)")
+	csvMode := fs.Bool("csv", false, "read CSV and re-encode the cells in -columns")
+	columns := fs.String("columns", "1", "comma-separated 1-based CSV columns holding tokens")
+	header := fs.Bool("header", false, "copy the first CSV row unchanged")
+	keepVariant := fs.Bool("keep-variant", false, "keep each token's variant instead of picking a random one")
+	failFast := fs.Bool("fail-fast", false, "stop at the first token that cannot be migrated")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *configPath == "" || *fromName == "" || *toName == "" {
+		fmt.Fprintln(stderr, "migrate: -config, -from and -to are required")
+		fs.Usage()
+		return 2
+	}
+	cols, err := parseColumns(*columns)
+	if err != nil {
+		fmt.Fprintf(stderr, "migrate: %v\n", err)
+		return 2
+	}
+
+	profiles, err := idmix.LoadProfilesFile(*configPath, *keysPath)
+	if err != nil {
+		fmt.Fprintf(stderr, "migrate: load profiles: %v\n", err)
+		return 2
+	}
+	m := &migrator{failFast: *failFast, report: stderr}
+	if m.from, err = profiles.Get(*fromName); err != nil {
+		fmt.Fprintf(stderr, "migrate: -from: %v\n", err)
+		return 2
+	}
+	if m.to, err = profiles.Get(*toName); err != nil {
+		fmt.Fprintf(stderr, "migrate: -to: %v\n", err)
+		return 2
+	}
+	if *keepVariant {
+		m.opts = append(m.opts, idmix.KeepVariant())
+	}
+
+	if *csvMode {
+		err = m.csv(stdin, stdout, cols, *header)
+	} else {
+		err = m.lines(stdin, stdout)
+	}
+	fmt.Fprintf(stderr, "migrate: %d tokens, %d failed\n", m.tokens, m.failures)
+	switch {
+	case errors.Is(err, errFailFast):
+		return 1
+	case err != nil:
+		fmt.Fprintf(stderr, "migrate: %v\n", err)
+		return 2
+	case m.failures > 0:
+		return 1
+	}
+	return 0
+}
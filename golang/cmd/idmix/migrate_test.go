@@ -0,0 +1,177 @@
+// migrate_test.go 覆盖 migrate 子命令：逐行与 CSV 模式的改写结果、失败行号报告与退出码。
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	idmix "github.com/Vanni-Fan/idmix/golang"
+)
+
+const testProfiles = `{"profiles": {
+	"old": {"checkBits": 1},
+	"new": {"alphabet": "0123456789ABCDEFGHJKMNPQRSTVWXYZ", "checksum": "crc8"}
+}}`
+
+// setup 写入测试 profile 文件，返回其路径与对应的 IdMix。
+func setup(t *testing.T) (config string, oldM, newM *idmix.IdMix) {
+	t.Helper()
+	config = filepath.Join(t.TempDir(), "profiles.json")
+	if err := os.WriteFile(config, []byte(testProfiles), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	profiles, err := idmix.LoadProfilesFile(config, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldM, _ = profiles.Get("old")
+	newM, _ = profiles.Get("new")
+	return config, oldM, newM
+}
+
+func migrate(t *testing.T, stdin string, args ...string) (code int, stdout, stderr string) {
+	t.Helper()
+	var out, errOut bytes.Buffer
+	code = run(append([]string{"migrate"}, args...), strings.NewReader(stdin), &out, &errOut)
+	return code, out.String(), errOut.String()
+}
+
+func TestMigrateLines(t *testing.T) {
+	config, oldM, newM := setup(t)
+	var in strings.Builder
+	var want [][]any
+	for i := 0; i < 50; i++ {
+		values := []any{uint32(1000 + i), "eu"}
+		s, _ := oldM.Encode(values...)
+		in.WriteString(s + "\n")
+		want = append(want, values)
+	}
+	in.WriteString("\n") // 空行原样保留
+
+	code, out, errOut := migrate(t, in.String(), "-config", config, "-from", "old", "-to", "new")
+	if code != 0 {
+		t.Fatalf("exit %d: %s", code, errOut)
+	}
+	lines := strings.Split(strings.TrimSuffix(out, "\n"), "\n")
+	if len(lines) != 51 || lines[50] != "" {
+		t.Fatalf("got %d lines", len(lines))
+	}
+	for i, values := range want {
+		got, err := newM.Decode(lines[i])
+		if err != nil || got[0] != values[0] || got[1] != values[1] {
+			t.Fatalf("line %d: %q => %v %v", i+1, lines[i], got, err)
+		}
+	}
+	t.Logf("%s => %s (%s)", strings.SplitN(in.String(), "\n", 2)[0], lines[0], strings.TrimSpace(errOut))
+}
+
+// TestMigrateLineEndings 行尾原样保留：CRLF 不改为 LF，末行无换行时不补。
+func TestMigrateLineEndings(t *testing.T) {
+	config, oldM, newM := setup(t)
+	a, _ := oldM.EncodeWithVariant(1, uint32(7))
+	b, _ := oldM.EncodeWithVariant(2, uint32(8))
+	wantA, _ := idmix.Transcode(a, oldM, newM, idmix.KeepVariant())
+	wantB, _ := idmix.Transcode(b, oldM, newM, idmix.KeepVariant())
+	cases := []struct{ name, in, want string }{
+		{"crlf", a + "\r\n\r\n" + b + "\r\n", wantA + "\r\n\r\n" + wantB + "\r\n"},
+		{"no_final_newline", a + "\n" + b, wantA + "\n" + wantB},
+		{"crlf_no_final_newline", " " + a + "\r\n" + b, " " + wantA + "\r\n" + wantB},
+		{"empty", "", ""},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			code, out, errOut := migrate(t, c.in, "-config", config, "-from", "old", "-to", "new", "-keep-variant")
+			if code != 0 {
+				t.Fatalf("exit %d: %s", code, errOut)
+			}
+			if out != c.want {
+				t.Fatalf("got %q, want %q", out, c.want)
+			}
+			t.Logf("%q => %q", c.in, out)
+		})
+	}
+}
+
+func TestMigrateFailures(t *testing.T) {
+	config, oldM, _ := setup(t)
+	good, _ := oldM.Encode(uint8(1))
+	in := good + "\n!!bad!!\n" + good + "\n"
+
+	code, out, errOut := migrate(t, in, "-config", config, "-from", "old", "-to", "new")
+	if code != 1 {
+		t.Fatalf("exit %d, want 1", code)
+	}
+	if lines := strings.Split(out, "\n"); len(lines) != 4 || lines[1] != "!!bad!!" {
+		t.Fatalf("failed token should be copied unchanged: %q", out)
+	}
+	if !strings.Contains(errOut, "line 2: \"!!bad!!\": decode: ") || !strings.Contains(errOut, "3 tokens, 1 failed") {
+		t.Fatalf("stderr: %s", errOut)
+	}
+	t.Logf("stderr:\n%s", errOut)
+
+	code, out, errOut = migrate(t, in, "-config", config, "-from", "old", "-to", "new", "-fail-fast")
+	if code != 1 || strings.Count(out, "\n") != 1 || !strings.Contains(errOut, "2 tokens, 1 failed") {
+		t.Fatalf("fail-fast: exit %d, out %q, stderr %s", code, out, errOut)
+	}
+}
+
+func TestMigrateCSV(t *testing.T) {
+	config, oldM, newM := setup(t)
+	a, _ := oldM.Encode(uint32(7))
+	b, _ := oldM.Encode(uint32(8), "x")
+	in := "id,name,parent\n" +
+		a + ",\"Alice, A.\"," + b + "\n" +
+		b + ",Bob,\n" +
+		"oops,\"multi\nline\",\n" +
+		a + ",Eve\n"
+
+	code, out, errOut := migrate(t, in, "-config", config, "-from", "old", "-to", "new", "-csv", "-columns", "1,3", "-header")
+	if code != 1 {
+		t.Fatalf("exit %d: %s", code, errOut)
+	}
+	// "oops" 位于第 4 行第 1 列；引号内的换行使下一条记录从第 6 行开始
+	if !strings.Contains(errOut, "line 4, column 1: \"oops\"") || !strings.Contains(errOut, "5 tokens, 1 failed") {
+		t.Fatalf("stderr: %s", errOut)
+	}
+	cr := csv.NewReader(strings.NewReader(out))
+	cr.FieldsPerRecord = -1
+	records, err := cr.ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if records[0][0] != "id" || records[1][1] != "Alice, A." || records[2][2] != "" || records[3][0] != "oops" {
+		t.Fatalf("unexpected output: %q", records)
+	}
+	for _, cell := range []string{records[1][0], records[1][2], records[2][0], records[4][0]} {
+		if _, err := newM.Decode(cell); err != nil {
+			t.Fatalf("%q: %v", cell, err)
+		}
+	}
+	if !newM.Equal(records[1][0], records[4][0]) {
+		t.Fatal("same token should migrate to equal values")
+	}
+	t.Logf("output:\n%s", out)
+}
+
+func TestMigrateUsage(t *testing.T) {
+	config, _, _ := setup(t)
+	for _, args := range [][]string{
+		{},
+		{"-config", config, "-from", "old"},
+		{"-config", config, "-from", "old", "-to", "missing"},
+		{"-config", config, "-from", "old", "-to", "new", "-columns", "0"},
+		{"-config", filepath.Join(t.TempDir(), "none.json"), "-from", "old", "-to", "new"},
+	} {
+		if code, _, _ := migrate(t, "", args...); code != 2 {
+			t.Fatalf("%v: exit %d, want 2", args, code)
+		}
+	}
+	var out bytes.Buffer
+	if code := run([]string{"bogus"}, nil, &out, &out); code != 2 {
+		t.Fatalf("unknown command: exit %d", code)
+	}
+}
@@ -3,6 +3,7 @@ package idmix
 
 import (
 	"encoding/base64"
+	"errors"
 	"sync"
 )
 
@@ -47,6 +48,19 @@ func (Base64Codec) Decode(s string) ([]byte, error) {
 	return base64.StdEncoding.DecodeString(s)
 }
 
+// NewXORCodec 包装 inner：编码前将 key 循环异或到二进制上，解码后逆操作。
+// 等价于 Pipeline(inner, NewXORTransform(key))。
+func NewXORCodec(inner Codec, key []byte) (Codec, error) {
+	if inner == nil {
+		return nil, errors.New("codec cannot be nil")
+	}
+	xor, err := NewXORTransform(key)
+	if err != nil {
+		return nil, err
+	}
+	return Pipeline(inner, xor)
+}
+
 var (
 	defaultCodec     Codec
 	defaultCodecOnce sync.Once
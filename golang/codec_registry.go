@@ -0,0 +1,215 @@
+// codec_registry.go 实现自描述的 Codec 注册表：编码时在输出前加上 codec 的短标识，
+// 解码时按前缀自动选择 codec，使不同文本层产生的编码串可以共存。
+//
+// 格式：id || codec.Encode(data)。id 为 1~4 个可打印 ASCII 字符，
+// 注册时保证任意两个 id 互不为前缀，因此前缀匹配结果唯一。
+// 引入注册表之前的无前缀编码串可通过 SetLegacy 指定的 codec 继续解码。
+package idmix
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// maxCodecIDLen 为 codec 标识的最大长度（字节）。
+const maxCodecIDLen = 4
+
+// ErrUnknownCodec 编码串不以任何已注册的 codec 标识开头。
+var ErrUnknownCodec = errors.New("unknown codec id")
+
+// UnknownCodecError 描述无法识别的前缀及当前已注册的标识。
+type UnknownCodecError struct {
+	Prefix     string
+	Registered []string
+}
+
+func (e *UnknownCodecError) Error() string {
+	if len(e.Registered) == 0 {
+		return fmt.Sprintf("%v %q: no codecs registered", ErrUnknownCodec, e.Prefix)
+	}
+	return fmt.Sprintf("%v %q (registered: %s)", ErrUnknownCodec, e.Prefix, strings.Join(e.Registered, ", "))
+}
+
+func (e *UnknownCodecError) Is(target error) bool { return target == ErrUnknownCodec }
+
+// CodecRegistry 按短标识保存多个 Codec，本身也实现 Codec，可并发使用。
+//
+// Encode 使用默认 codec（首个注册的，或 SetDefault 指定的）并加上其标识；
+// Decode 按前缀分派到对应 codec，没有匹配的前缀时交给 legacy codec（如已设置）。
+type CodecRegistry struct {
+	mu        sync.RWMutex
+	codecs    map[string]Codec
+	defaultID string
+	legacy    Codec
+}
+
+// NewCodecRegistry 创建空注册表。
+func NewCodecRegistry() *CodecRegistry {
+	return &CodecRegistry{codecs: make(map[string]Codec)}
+}
+
+// Register 以 id 注册 codec。id 须为 1~4 个可打印 ASCII 字符，
+// 且不能与已注册的 id 相同或互为前缀。
+func (r *CodecRegistry) Register(id string, codec Codec) error {
+	if codec == nil {
+		return errors.New("codec cannot be nil")
+	}
+	if err := validateCodecID(id); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for other := range r.codecs {
+		if strings.HasPrefix(id, other) || strings.HasPrefix(other, id) {
+			return fmt.Errorf("codec id %q conflicts with registered id %q", id, other)
+		}
+	}
+	r.codecs[id] = codec
+	if r.defaultID == "" {
+		r.defaultID = id
+	}
+	return nil
+}
+
+func validateCodecID(id string) error {
+	if id == "" || len(id) > maxCodecIDLen {
+		return fmt.Errorf("codec id %q must be 1-%d characters", id, maxCodecIDLen)
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return fmt.Errorf("codec id %q contains non-printable or non-ASCII byte %#x", id, id[i])
+		}
+	}
+	return nil
+}
+
+// SetDefault 指定 Encode 使用的 codec。
+func (r *CodecRegistry) SetDefault(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.codecs[id]; !ok {
+		return r.unknownLocked(id)
+	}
+	r.defaultID = id
+	return nil
+}
+
+// SetLegacy 设置解码无前缀编码串使用的 codec（nil 为取消），只用于 Decode，不影响 Encode。
+//
+// 没有任何已注册标识与 s 的开头匹配时才使用 legacy codec，因此标识应选用不在旧字符表中的字符
+// （如默认 62 进制字符表配 "~r"、"~b"），否则以标识字符开头的旧编码串会被分派到已注册的 codec。
+func (r *CodecRegistry) SetLegacy(codec Codec) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.legacy = codec
+}
+
+// Legacy 返回 SetLegacy 设置的 codec；未设置时为 nil。
+func (r *CodecRegistry) Legacy() Codec {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.legacy
+}
+
+// Default 返回 Encode 使用的 codec 标识；未注册任何 codec 时为空。
+func (r *CodecRegistry) Default() string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.defaultID
+}
+
+// Codec 返回 id 对应的 codec。
+func (r *CodecRegistry) Codec(id string) (Codec, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	c, ok := r.codecs[id]
+	return c, ok
+}
+
+// IDs 返回已注册的标识（升序）。
+func (r *CodecRegistry) IDs() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.idsLocked()
+}
+
+func (r *CodecRegistry) idsLocked() []string {
+	ids := make([]string, 0, len(r.codecs))
+	for id := range r.codecs {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func (r *CodecRegistry) unknownLocked(prefix string) error {
+	return &UnknownCodecError{Prefix: prefix, Registered: r.idsLocked()}
+}
+
+// Encode 使用默认 codec 编码并加上其标识。
+func (r *CodecRegistry) Encode(data []byte) (string, error) {
+	r.mu.RLock()
+	id := r.defaultID
+	r.mu.RUnlock()
+	if id == "" {
+		return "", errors.New("codec registry is empty")
+	}
+	return r.EncodeWith(id, data)
+}
+
+// EncodeWith 使用 id 对应的 codec 编码并加上标识。
+func (r *CodecRegistry) EncodeWith(id string, data []byte) (string, error) {
+	c, ok := r.Codec(id)
+	if !ok {
+		r.mu.RLock()
+		defer r.mu.RUnlock()
+		return "", r.unknownLocked(id)
+	}
+	s, err := c.Encode(data)
+	if err != nil {
+		return "", err
+	}
+	return id + s, nil
+}
+
+// Identify 返回 s 的 codec 标识；无法识别时返回 *UnknownCodecError。
+func (r *CodecRegistry) Identify(s string) (string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for n := 1; n <= maxCodecIDLen && n <= len(s); n++ {
+		if _, ok := r.codecs[s[:n]]; ok {
+			return s[:n], nil
+		}
+	}
+	prefix := s
+	if len(prefix) > maxCodecIDLen {
+		prefix = prefix[:maxCodecIDLen]
+	}
+	return "", r.unknownLocked(prefix)
+}
+
+// Decode 按前缀选择 codec 并解码其余部分；没有匹配的前缀时以 legacy codec 解码整个 s，
+// 未设置 legacy codec 时返回 *UnknownCodecError。
+func (r *CodecRegistry) Decode(s string) ([]byte, error) {
+	id, err := r.Identify(s)
+	if err != nil {
+		legacy := r.Legacy()
+		if legacy == nil {
+			return nil, err
+		}
+		data, lerr := legacy.Decode(s)
+		if lerr != nil {
+			return nil, fmt.Errorf("%w; legacy codec: %w", err, lerr)
+		}
+		return data, nil
+	}
+	c, _ := r.Codec(id)
+	data, err := c.Decode(s[len(id):])
+	if err != nil {
+		return nil, fmt.Errorf("codec %q: %w", id, err)
+	}
+	return data, nil
+}
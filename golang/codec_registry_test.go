@@ -0,0 +1,156 @@
+// codec_registry_test.go 覆盖 CodecRegistry 的前缀分派、标识冲突与未知标识错误。
+package idmix
+
+import (
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// TestCodecRegistryDispatch 三种文本层的编码串由同一个注册表自动识别并解码。
+func TestCodecRegistryDispatch(t *testing.T) {
+	hexCodec := FuncCodec{
+		EncodeFn: func(data []byte) (string, error) { return hex.EncodeToString(data), nil },
+		DecodeFn: hex.DecodeString,
+	}
+	reg := NewCodecRegistry()
+	for _, c := range []struct {
+		id    string
+		codec Codec
+	}{{"r", defaultCodecInstance()}, {"b", NewBase64Codec()}, {"x", hexCodec}} {
+		if err := reg.Register(c.id, c.codec); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if reg.Default() != "r" || strings.Join(reg.IDs(), ",") != "b,r,x" {
+		t.Fatalf("default %q ids %v", reg.Default(), reg.IDs())
+	}
+
+	m, err := New(WithCodec(reg))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := m.Idx().EncodeWithVariant(2, uint32(1001), "eu")
+	for _, id := range reg.IDs() {
+		s, err := reg.EncodeWith(id, data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, _ := reg.Identify(s); got != id {
+			t.Fatalf("%q identified as %q", s, got)
+		}
+		list, err := m.Decode(s)
+		if err != nil || list[0].(uint32) != 1001 || list[1].(string) != "eu" {
+			t.Fatalf("%s: %v %v", id, list, err)
+		}
+		t.Logf("%s => %q", id, s)
+	}
+
+	if err := reg.SetDefault("x"); err != nil {
+		t.Fatal(err)
+	}
+	s, _ := m.Encode(uint8(7))
+	if !strings.HasPrefix(s, "x") {
+		t.Fatalf("SetDefault ignored: %q", s)
+	}
+}
+
+func TestCodecRegistryErrors(t *testing.T) {
+	reg := NewCodecRegistry()
+	if _, err := reg.Encode([]byte{1}); err == nil {
+		t.Fatal("expected empty registry error")
+	}
+	if _, err := reg.Decode("r123"); !errors.Is(err, ErrUnknownCodec) {
+		t.Fatalf("empty registry decode: %v", err)
+	}
+	_ = reg.Register("v1", NewBase64Codec())
+	_ = reg.Register("z", NewZ85Codec())
+
+	for _, c := range []struct {
+		id    string
+		codec Codec
+	}{
+		{"v1", NewBase58Codec()},    // 重复
+		{"v", NewBase58Codec()},     // 是 v1 的前缀
+		{"z9", NewBase58Codec()},    // 以 z 为前缀
+		{"", NewBase58Codec()},      // 空
+		{"abcde", NewBase58Codec()}, // 过长
+		{"a b", NewBase58Codec()},   // 含空格
+		{"é", NewBase58Codec()},     // 非 ASCII
+		{"ok", nil},
+	} {
+		if err := reg.Register(c.id, c.codec); err == nil {
+			t.Fatalf("Register(%q) should fail", c.id)
+		} else {
+			t.Logf("Register(%q) => %v", c.id, err)
+		}
+	}
+
+	for _, s := range []string{"", "v2abc", "qqqqqq"} {
+		_, err := reg.Decode(s)
+		var ue *UnknownCodecError
+		if !errors.As(err, &ue) || !errors.Is(err, ErrUnknownCodec) {
+			t.Fatalf("Decode(%q): %v", s, err)
+		}
+		if len(ue.Prefix) > maxCodecIDLen || strings.Join(ue.Registered, ",") != "v1,z" {
+			t.Fatalf("Decode(%q): %+v", s, ue)
+		}
+		t.Logf("Decode(%q) => %v", s, err)
+	}
+	if _, err := reg.EncodeWith("q", []byte{1}); !errors.Is(err, ErrUnknownCodec) {
+		t.Fatalf("EncodeWith unknown: %v", err)
+	}
+	if err := reg.SetDefault("q"); !errors.Is(err, ErrUnknownCodec) {
+		t.Fatalf("SetDefault unknown: %v", err)
+	}
+	// 已识别标识但内容非法时，错误中带上标识
+	if _, err := reg.Decode("v1!!"); err == nil || !strings.Contains(err.Error(), `codec "v1"`) {
+		t.Fatalf("inner error: %v", err)
+	}
+}
+
+// TestCodecRegistryLegacy 引入注册表前的无前缀编码串由 legacy codec 解码，新编码串照常按前缀分派。
+func TestCodecRegistryLegacy(t *testing.T) {
+	old, _ := New()
+	legacyTokens := make([]string, 0, 50)
+	for i := 0; i < 50; i++ {
+		s, _ := old.Encode(uint32(i*7919), "eu")
+		legacyTokens = append(legacyTokens, s)
+	}
+
+	reg := NewCodecRegistry()
+	_ = reg.Register("~r", defaultCodecInstance())
+	_ = reg.Register("~b", NewBase64URLCodec())
+	m, _ := New(WithCodec(reg))
+	if _, err := m.Decode(legacyTokens[0]); !errors.Is(err, ErrUnknownCodec) {
+		t.Fatalf("without legacy codec: %v", err)
+	}
+	reg.SetLegacy(defaultCodecInstance())
+	if reg.Legacy() == nil {
+		t.Fatal("Legacy() = nil")
+	}
+	for i, s := range legacyTokens {
+		list, err := m.Decode(s)
+		if err != nil || list[0].(uint32) != uint32(i*7919) || list[1].(string) != "eu" {
+			t.Fatalf("legacy %q: %v %v", s, list, err)
+		}
+	}
+	_ = reg.SetDefault("~b")
+	s, _ := m.Encode(uint32(42))
+	if list, err := m.Decode(s); err != nil || !strings.HasPrefix(s, "~b") || list[0].(uint32) != 42 {
+		t.Fatalf("prefixed %q: %v %v", s, list, err)
+	}
+	t.Logf("legacy %q, prefixed %q", legacyTokens[0], s)
+
+	// legacy codec 也无法解码时，错误同时保留未知前缀与 legacy 的原因
+	_, err := reg.Decode("!!")
+	if !errors.Is(err, ErrUnknownCodec) || !strings.Contains(err.Error(), "legacy codec") {
+		t.Fatalf("legacy failure: %v", err)
+	}
+	t.Logf("%v", err)
+	reg.SetLegacy(nil)
+	if _, err := reg.Decode(legacyTokens[0]); !errors.Is(err, ErrUnknownCodec) {
+		t.Fatalf("after SetLegacy(nil): %v", err)
+	}
+}
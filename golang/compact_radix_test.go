@@ -0,0 +1,136 @@
+// compact_radix_test.go 覆盖紧凑 RadixCodec：固定向量、双射性与相对标准模式的长度。
+package idmix
+
+import (
+	"bytes"
+	"encoding/hex"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+// TestCompactRadixVectors 固定向量（默认 62 字符表），供其他语言实现比对。
+func TestCompactRadixVectors(t *testing.T) {
+	rc, err := NewCompactRadixCodec(DefaultAlphabet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		hex     string
+		encoded string
+	}{
+		{"", ""},
+		{"00", "a"},
+		{"0000", "di"},
+		{"ff", "dh"},
+		{"0100", "hq"},
+		{"0216", "lU"},
+		{"033d", "qF"},
+		{"68656c6c6f", "g7hijgD"},
+		{"000102030405060708090a0b0c0d0e0f", "a23FLTz9zP3MbmZZRC71Z"},
+	}
+	for _, c := range cases {
+		data, _ := hex.DecodeString(c.hex)
+		s, err := rc.Encode(data)
+		if err != nil || s != c.encoded {
+			t.Fatalf("%s: got %q %v, want %q", c.hex, s, err, c.encoded)
+		}
+		back, err := rc.Decode(s)
+		if err != nil || !bytes.Equal(back, data) {
+			t.Fatalf("%q: got %x %v", s, back, err)
+		}
+		t.Logf("%-34s => %q", c.hex, s)
+	}
+	crockford, _ := NewCompactRadixCodec(AlphabetCrockford32)
+	if s, _ := crockford.Encode([]byte{0x02, 0x16}); s != "QP" {
+		t.Fatalf("crockford: %q", s)
+	}
+	if _, err := rc.Decode("ab!"); err == nil {
+		t.Fatal("expected invalid character error")
+	}
+}
+
+// TestCompactRadixBijective 任意字节串往返，且任意字符串都能解码并重新编码为原串。
+func TestCompactRadixBijective(t *testing.T) {
+	rc, _ := NewCompactRadixCodec("abc")
+	r := rand.New(rand.NewSource(9))
+	for i := 0; i < 2000; i++ {
+		data := make([]byte, r.Intn(12))
+		for j := range data {
+			data[j] = byte(r.Intn(3)) // 偏向前导 0x00
+		}
+		s, _ := rc.Encode(data)
+		back, err := rc.Decode(s)
+		if err != nil || !bytes.Equal(back, data) {
+			t.Fatalf("%x => %q => %x %v", data, s, back, err)
+		}
+	}
+	alphabet := []rune("abc")
+	for i := 0; i < 2000; i++ {
+		runes := make([]rune, r.Intn(10))
+		for j := range runes {
+			runes[j] = alphabet[r.Intn(3)]
+		}
+		data, err := rc.Decode(string(runes))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s, _ := rc.Encode(data); s != string(runes) {
+			t.Fatalf("%q => %x => %q", string(runes), data, s)
+		}
+	}
+}
+
+// TestCompactRadixShorter 紧凑模式与 IdMix、WithSecretAlphabet、Profile 组合，并统计长度。
+func TestCompactRadixShorter(t *testing.T) {
+	rc, _ := NewCompactRadixCodec(DefaultAlphabet)
+	compact, err := New(WithCodec(rc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	standard, _ := New()
+	saved := 0
+	for _, c := range extremeValueCases() {
+		for v := 0; v < 32; v++ {
+			a, err := standard.EncodeWithVariant(v, c.vals...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			b, err := compact.EncodeWithVariant(v, c.vals...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(b) > len(a) {
+				t.Fatalf("%s v=%d: compact %q longer than %q", c.name, v, b, a)
+			}
+			saved += len(a) - len(b)
+			list, err := compact.Decode(b)
+			if err != nil || !reflect.DeepEqual(list, c.vals) {
+				t.Fatalf("%s: %v %v", c.name, list, err)
+			}
+		}
+		a, _ := standard.EncodeWithVariant(0, c.vals...)
+		b, _ := compact.EncodeWithVariant(0, c.vals...)
+		t.Logf("%-16s standard %2d %-24q compact %2d %q", c.name, len(a), a, len(b), b)
+	}
+	avg := float64(saved) / float64(32*len(extremeValueCases()))
+	t.Logf("average saving: %.2f chars", avg)
+	if avg < 0.5 {
+		t.Fatalf("average saving %.2f < 0.5 char", avg)
+	}
+
+	secret, err := New(WithCodec(rc), WithSecretAlphabet([]byte("k")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !secret.Codec().(*RadixCodec).Compact() {
+		t.Fatal("WithSecretAlphabet should keep compact mode")
+	}
+	fromProfile, err := NewFromProfile(Profile{Codec: CodecCompactRadix, Alphabet: AlphabetCrockford32}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pc := fromProfile.Codec().(*RadixCodec); !pc.Compact() || pc.Alphabet() != AlphabetCrockford32 {
+		t.Fatalf("profile codec %+v", pc)
+	}
+}
@@ -3,6 +3,7 @@ package idmix
 
 import (
 	"fmt"
+	"reflect"
 	"testing"
 )
 
@@ -102,3 +103,42 @@ func materializeFromOtypeVal(otype uint8, val int64) any {
 		panic(fmt.Sprintf("invalid otype %d", otype))
 	}
 }
+
+// TestTypedValueVectors 验证 TypedValue 与向量文件中的 {otype, val} 结构互转一致。
+func TestTypedValueVectors(t *testing.T) {
+	f := loadCrossLanguageVectors(t)
+	for _, c := range f.Cases {
+		for i, v := range c.Values {
+			tv := TypedValue{Str: v.Str}
+			if v.Str == "" {
+				otype := v.OType
+				tv = TypedValue{OType: &otype, Val: v.Val}
+			}
+			val, err := tv.Value()
+			if err != nil {
+				t.Fatalf("%s[%d]: %v", c.Name, i, err)
+			}
+			back, err := NewTypedValue(val)
+			if err != nil {
+				t.Fatalf("%s[%d]: %v", c.Name, i, err)
+			}
+			if !reflect.DeepEqual(back, tv) {
+				t.Fatalf("%s[%d]: got %+v, want %+v", c.Name, i, back, tv)
+			}
+		}
+	}
+	otype := func(n int) *int { return &n }
+	bad := []TypedValue{
+		{OType: otype(8), Val: "1"},
+		{OType: otype(otypeUint8), Val: "256"},
+		{OType: otype(otypeInt32), Val: "x"},
+		{Val: "1"},                            // 缺省 otype 不视为 uint8
+		{Str: "eu", OType: otype(otypeUint8)}, // 字符串不得带 otype
+		{Str: "eu", Val: "1"},
+	}
+	for _, bad := range bad {
+		if _, err := bad.Value(); err == nil {
+			t.Fatalf("%+v should be rejected", bad)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+// fallback.go 实现多配置回退解码：迁移期间同时接受新旧配置产生的编码串。
+//
+// FallbackDecoder 按顺序尝试每个配置，并且总是尝试全部配置：一个串若能被多个配置解码
+// （不同字符表恰好重叠、校验位偶然通过等），返回 *AmbiguousTokenError 而不是静默采用第一个。
+package idmix
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrNoConfigMatched 编码串不能被任何配置解码（可用 errors.Is 判断）。
+	ErrNoConfigMatched = errors.New("no configuration decodes token")
+	// ErrAmbiguousToken 编码串能被多个配置解码（可用 errors.Is 判断）。
+	ErrAmbiguousToken = errors.New("token decodes under multiple configurations")
+)
+
+// FallbackConfig 为 FallbackDecoder 中的一个具名配置。
+type FallbackConfig struct {
+	Name  string
+	IdMix *IdMix
+}
+
+// FallbackMatch 为解码成功的配置及其结果；Index 为配置在 FallbackDecoder 中的位置。
+type FallbackMatch struct {
+	Name   string
+	Index  int
+	Values []any
+}
+
+// FallbackAttempt 为某个配置解码失败的原因。
+type FallbackAttempt struct {
+	Name string
+	Err  error
+}
+
+// NoConfigMatchedError 为 ErrNoConfigMatched 的具体错误，按配置顺序列出各自的失败原因。
+type NoConfigMatchedError struct {
+	Attempts []FallbackAttempt
+}
+
+func (e *NoConfigMatchedError) Error() string {
+	parts := make([]string, len(e.Attempts))
+	for i, a := range e.Attempts {
+		parts[i] = fmt.Sprintf("%s: %v", a.Name, a.Err)
+	}
+	return fmt.Sprintf("%v (%s)", ErrNoConfigMatched, strings.Join(parts, "; "))
+}
+
+func (e *NoConfigMatchedError) Is(target error) bool { return target == ErrNoConfigMatched }
+
+// AmbiguousTokenError 为 ErrAmbiguousToken 的具体错误，Matches 按配置顺序列出全部解码结果，
+// 调用方可据此自行裁决（例如按 Values 是否一致）。
+type AmbiguousTokenError struct {
+	Token   string
+	Matches []FallbackMatch
+}
+
+func (e *AmbiguousTokenError) Error() string {
+	names := make([]string, len(e.Matches))
+	for i, m := range e.Matches {
+		names[i] = m.Name
+	}
+	return fmt.Sprintf("%v %q: %s", ErrAmbiguousToken, e.Token, strings.Join(names, ", "))
+}
+
+func (e *AmbiguousTokenError) Is(target error) bool { return target == ErrAmbiguousToken }
+
+// FallbackDecoder 按顺序持有多个配置，构造后只读，可并发使用。
+type FallbackDecoder struct {
+	configs []FallbackConfig
+}
+
+// NewFallbackDecoder 创建回退解码器；configs 按优先级排列（通常新配置在前），名称须非空且互不相同。
+func NewFallbackDecoder(configs ...FallbackConfig) (*FallbackDecoder, error) {
+	if len(configs) == 0 {
+		return nil, errors.New("at least one configuration is required")
+	}
+	seen := make(map[string]bool, len(configs))
+	for i, c := range configs {
+		if c.Name == "" {
+			return nil, fmt.Errorf("configuration %d: name is required", i)
+		}
+		if c.IdMix == nil {
+			return nil, fmt.Errorf("configuration %q: IdMix cannot be nil", c.Name)
+		}
+		if seen[c.Name] {
+			return nil, fmt.Errorf("duplicate configuration name %q", c.Name)
+		}
+		seen[c.Name] = true
+	}
+	return &FallbackDecoder{configs: append([]FallbackConfig(nil), configs...)}, nil
+}
+
+// Names 返回按顺序排列的配置名。
+func (d *FallbackDecoder) Names() []string {
+	names := make([]string, len(d.configs))
+	for i, c := range d.configs {
+		names[i] = c.Name
+	}
+	return names
+}
+
+// Decode 用全部配置尝试解码 s。恰好一个配置成功时返回其结果；
+// 多个成功时返回 *AmbiguousTokenError；全部失败时返回 *NoConfigMatchedError。
+func (d *FallbackDecoder) Decode(s string) (*FallbackMatch, error) {
+	matches, attempts := d.decodeAll(s)
+	switch len(matches) {
+	case 0:
+		return nil, &NoConfigMatchedError{Attempts: attempts}
+	case 1:
+		return &matches[0], nil
+	}
+	return nil, &AmbiguousTokenError{Token: s, Matches: matches}
+}
+
+// Matches 返回能解码 s 的全部配置（按顺序），用于离线扫描存量数据中的歧义串。
+func (d *FallbackDecoder) Matches(s string) []FallbackMatch {
+	matches, _ := d.decodeAll(s)
+	return matches
+}
+
+func (d *FallbackDecoder) decodeAll(s string) ([]FallbackMatch, []FallbackAttempt) {
+	var matches []FallbackMatch
+	var attempts []FallbackAttempt
+	for i, c := range d.configs {
+		values, err := c.IdMix.Decode(s)
+		if err != nil {
+			attempts = append(attempts, FallbackAttempt{Name: c.Name, Err: err})
+			continue
+		}
+		matches = append(matches, FallbackMatch{Name: c.Name, Index: i, Values: values})
+	}
+	return matches, attempts
+}
@@ -0,0 +1,103 @@
+// fallback_test.go 覆盖多配置回退解码：按配置区分新旧编码串、歧义串的报告与全部失败时的错误。
+package idmix
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// TestFallbackDecoder 新旧配置的编码串各自匹配到正确的配置；偶发的歧义串必须报告而不是取第一个。
+func TestFallbackDecoder(t *testing.T) {
+	oldIdx, _ := NewIdx(WithCheckBits(1))
+	oldM, _ := New(WithIdx(oldIdx))
+	newIdx, _ := NewIdx(WithChecksum(ChecksumCRC8))
+	newM, _ := New(WithIdx(newIdx), WithCodec(mustRadix(t, AlphabetCrockford32)))
+	d, err := NewFallbackDecoder(FallbackConfig{Name: "new", IdMix: newM}, FallbackConfig{Name: "old", IdMix: oldM})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if names := d.Names(); !reflect.DeepEqual(names, []string{"new", "old"}) {
+		t.Fatalf("Names() = %v", names)
+	}
+
+	ambiguous := 0
+	for i := 0; i < 200; i++ {
+		values := []any{uint32(i), "eu"}
+		for _, want := range []FallbackConfig{{"new", newM}, {"old", oldM}} {
+			s := mustEncode(t, want.IdMix, values...)
+			match, err := d.Decode(s)
+			if errors.Is(err, ErrAmbiguousToken) {
+				ambiguous++
+				continue
+			}
+			if err != nil || match.Name != want.Name || !reflect.DeepEqual(match.Values, values) {
+				t.Fatalf("%s token %q: %+v %v", want.Name, s, match, err)
+			}
+		}
+	}
+	t.Logf("400 tokens, %d ambiguous", ambiguous)
+}
+
+// TestFallbackAmbiguous 构造能被两个配置解码的串（checkBits 2 与 1 的配置只靠校验位区分），确认报告全部匹配。
+func TestFallbackAmbiguous(t *testing.T) {
+	oldIdx, _ := NewIdx(WithCheckBits(1))
+	orders, _ := New()
+	users, _ := New(WithIdx(oldIdx))
+	d, _ := NewFallbackDecoder(FallbackConfig{Name: "orders", IdMix: orders}, FallbackConfig{Name: "users", IdMix: users})
+
+	found := false
+	for i := 0; i < 64*32 && !found; i++ {
+		v := i % 32
+		s, _ := orders.EncodeWithVariant(v, uint32(i/32))
+		if _, err := users.Decode(s); err != nil {
+			if match, err := d.Decode(s); err != nil || match.Name != "orders" || match.Index != 0 {
+				t.Fatalf("%q: %+v %v", s, match, err)
+			}
+			continue
+		}
+		found = true
+		_, err := d.Decode(s)
+		var ae *AmbiguousTokenError
+		if !errors.As(err, &ae) || !errors.Is(err, ErrAmbiguousToken) {
+			t.Fatalf("%q: %v", s, err)
+		}
+		if ae.Token != s || len(ae.Matches) != 2 || ae.Matches[0].Name != "orders" || ae.Matches[1].Name != "users" || ae.Matches[1].Index != 1 {
+			t.Fatalf("matches: %+v", ae.Matches)
+		}
+		if got := d.Matches(s); len(got) != 2 {
+			t.Fatalf("Matches() = %+v", got)
+		}
+		t.Logf("variant %d => %v (orders=%v users=%v)", v, err, ae.Matches[0].Values, ae.Matches[1].Values)
+	}
+	if !found {
+		t.Fatal("no colliding variant found")
+	}
+}
+
+// TestFallbackNoMatch 全部配置失败时按顺序列出各自的原因；构造参数校验。
+func TestFallbackNoMatch(t *testing.T) {
+	a, _ := New()
+	b, _ := New(WithCodec(NewBase64URLCodec()))
+	d, _ := NewFallbackDecoder(FallbackConfig{Name: "a", IdMix: a}, FallbackConfig{Name: "b", IdMix: b})
+	_, err := d.Decode("!!")
+	var ne *NoConfigMatchedError
+	if !errors.As(err, &ne) || !errors.Is(err, ErrNoConfigMatched) || len(ne.Attempts) != 2 || ne.Attempts[0].Name != "a" || ne.Attempts[1].Name != "b" {
+		t.Fatalf("%v", err)
+	}
+	if d.Matches("!!") != nil {
+		t.Fatal("Matches should be empty")
+	}
+	t.Logf("%v", err)
+
+	for _, configs := range [][]FallbackConfig{
+		nil,
+		{{Name: "", IdMix: a}},
+		{{Name: "a", IdMix: nil}},
+		{{Name: "a", IdMix: a}, {Name: "a", IdMix: b}},
+	} {
+		if _, err := NewFallbackDecoder(configs...); err == nil {
+			t.Fatalf("%+v: expected error", configs)
+		}
+	}
+}
@@ -3,10 +3,14 @@ module github.com/Vanni-Fan/idmix/golang
 go 1.23
 
 require (
-	github.com/fxamacker/cbor/v2 v2.9.2 // indirect
-	github.com/sqids/sqids-go v0.4.1 // indirect
-	github.com/vmihailenco/msgpack/v5 v5.4.1 // indirect
+	github.com/fxamacker/cbor/v2 v2.9.2
+	github.com/sqids/sqids-go v0.4.1
+	github.com/vmihailenco/msgpack/v5 v5.4.1
+	golang.org/x/text v0.22.0
+	google.golang.org/protobuf v1.36.11
+)
+
+require (
 	github.com/vmihailenco/tagparser/v2 v2.0.0 // indirect
 	github.com/x448/float16 v0.8.4 // indirect
-	google.golang.org/protobuf v1.36.11 // indirect
 )
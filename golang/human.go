@@ -0,0 +1,325 @@
+// human.go 实现面向人工输入的文本层包装：校验字符、分组显示与易混淆字符归一化。
+//
+// 编码：inner.Encode → 追加 1 个 mod N 校验字符 → 每 groupSize 个字符插入分隔符，
+// 例如 "7KQ2-M9XD-3"。解码时依次去除分隔符与空白、折叠大小写（字符表不区分大小写时）、
+// 映射易混淆字符（O→0、I/L→1 等），再校验并交给 inner.Decode。
+//
+// 校验字符为 1/2 交替加权和 mod N（N 为字符表长度）。N 为偶数时 2 不可逆，
+// 按 Luhn mod N 把 2·d 折叠为 2·d/N + 2·d%N；N 为奇数时 2 与 N 互素，直接取 2·d mod N
+// （Luhn 的折叠在奇数 N 下不是双射，会漏检部分替换）。两种情况都能检出全部单字符替换，
+// 奇数 N 还能检出全部相邻字符对调，偶数 N 漏检个别对调。校验失败时
+// 返回 *TypoError，列出能通过校验且可被 inner 解码的单字符修正。
+// 经 IdMix.Decode 解码时，候选还须能被 IDX 层解码，通常只剩一两个。
+package idmix
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// ErrCheckCharacter 校验字符不匹配（可用 errors.Is 判断）。
+var ErrCheckCharacter = errors.New("check character mismatch")
+
+// Suggestion 为一个单字符修正候选：把第 Pos 个字符（不含分隔符，从 0 起）由 Got 改为 Want。
+type Suggestion struct {
+	Pos  int
+	Got  rune
+	Want rune
+	// Code 为修正后的完整编码串（已分组）。
+	Code string
+}
+
+// TypoError 为校验失败时的错误，Suggestions 按位置排序，可能为空。
+type TypoError struct {
+	Suggestions []Suggestion
+}
+
+func (e *TypoError) Error() string {
+	if len(e.Suggestions) == 0 {
+		return ErrCheckCharacter.Error()
+	}
+	parts := make([]string, len(e.Suggestions))
+	for i, s := range e.Suggestions {
+		parts[i] = fmt.Sprintf("position %d %q→%q", s.Pos, s.Got, s.Want)
+	}
+	return fmt.Sprintf("%v; likely typo: %s", ErrCheckCharacter, strings.Join(parts, ", "))
+}
+
+// Is 使 errors.Is(err, ErrCheckCharacter) 成立。
+func (e *TypoError) Is(target error) bool {
+	return target == ErrCheckCharacter
+}
+
+// DefaultLookalikes 为默认的易混淆字符映射，只有目标字符在字符表内、源字符不在字符表内的项生效。
+var DefaultLookalikes = map[rune]rune{
+	'O': '0', 'o': '0',
+	'I': '1', 'i': '1', 'L': '1', 'l': '1',
+}
+
+// HumanCodec 包装 RadixCodec，输出便于抄写与人工输入的编码串。
+type HumanCodec struct {
+	inner      *RadixCodec
+	groupSize  int
+	separator  rune
+	foldCase   bool
+	lookalikes map[rune]rune
+}
+
+// HumanOption 配置 HumanCodec。
+type HumanOption func(*HumanCodec) error
+
+// WithGroupSize 设置分组长度（默认 4）；0 表示不分组。
+func WithGroupSize(n int) HumanOption {
+	return func(h *HumanCodec) error {
+		if n < 0 {
+			return errors.New("group size cannot be negative")
+		}
+		h.groupSize = n
+		return nil
+	}
+}
+
+// WithSeparator 设置分组分隔符（默认 '-'），不能出现在字符表中。
+func WithSeparator(sep rune) HumanOption {
+	return func(h *HumanCodec) error {
+		if _, ok := h.inner.fromCustom[sep]; ok {
+			return fmt.Errorf("separator %q is in the alphabet", sep)
+		}
+		h.separator = sep
+		return nil
+	}
+}
+
+// WithLookalikes 替换易混淆字符映射（源字符 → 字符表内字符）；传 nil 关闭映射。
+func WithLookalikes(m map[rune]rune) HumanOption {
+	return func(h *HumanCodec) error {
+		h.lookalikes = make(map[rune]rune, len(m))
+		for from, to := range m {
+			if _, ok := h.inner.fromCustom[from]; ok {
+				return fmt.Errorf("lookalike %q is in the alphabet", from)
+			}
+			if _, ok := h.inner.fromCustom[to]; !ok {
+				return fmt.Errorf("lookalike target %q is not in the alphabet", to)
+			}
+			h.lookalikes[from] = to
+		}
+		return nil
+	}
+}
+
+// NewHumanCodec 包装 inner。字符表中没有仅大小写不同的字符时，解码自动折叠大小写。
+func NewHumanCodec(inner *RadixCodec, opts ...HumanOption) (*HumanCodec, error) {
+	if inner == nil {
+		return nil, errors.New("codec cannot be nil")
+	}
+	h := &HumanCodec{
+		inner:     inner,
+		groupSize: 4,
+		separator: '-',
+		foldCase:  caseInsensitiveAlphabet(inner.chars),
+	}
+	h.lookalikes = make(map[rune]rune)
+	for from, to := range DefaultLookalikes {
+		_, fromIn := inner.fromCustom[from]
+		_, toIn := inner.fromCustom[to]
+		if !fromIn && toIn {
+			h.lookalikes[from] = to
+		}
+	}
+	if _, ok := inner.fromCustom[h.separator]; ok {
+		h.separator = ' '
+	}
+	for _, opt := range opts {
+		if err := opt(h); err != nil {
+			return nil, err
+		}
+	}
+	if _, ok := inner.fromCustom[h.separator]; ok && h.groupSize > 0 {
+		return nil, fmt.Errorf("separator %q is in the alphabet", h.separator)
+	}
+	return h, nil
+}
+
+// caseInsensitiveAlphabet 判断字符表中是否不存在仅大小写不同的两个字符。
+func caseInsensitiveAlphabet(chars []rune) bool {
+	seen := make(map[rune]bool, len(chars))
+	for _, r := range chars {
+		f := unicode.ToUpper(r)
+		if seen[f] {
+			return false
+		}
+		seen[f] = true
+	}
+	return true
+}
+
+// Encode 实现 Codec：inner 编码后追加校验字符并分组。
+func (h *HumanCodec) Encode(data []byte) (string, error) {
+	s, err := h.inner.Encode(data)
+	if err != nil {
+		return "", err
+	}
+	digits := h.digits([]rune(s))
+	return h.group(append([]rune(s), h.inner.chars[h.checkDigit(digits)])), nil
+}
+
+// Decode 实现 Codec：归一化输入、校验后交给 inner 解码。
+func (h *HumanCodec) Decode(s string) ([]byte, error) {
+	runes, err := h.normalize(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(runes) < 2 {
+		return nil, errors.New("code too short")
+	}
+	if !h.valid(h.digits(runes)) {
+		return nil, &TypoError{Suggestions: h.suggest(runes)}
+	}
+	return h.inner.Decode(string(runes[:len(runes)-1]))
+}
+
+// Normalize 返回 s 归一化并重新分组后的规范写法（不校验校验字符）。
+func (h *HumanCodec) Normalize(s string) (string, error) {
+	runes, err := h.normalize(s)
+	if err != nil {
+		return "", err
+	}
+	return h.group(runes), nil
+}
+
+// normalize 去除分隔符与空白，折叠大小写并映射易混淆字符。
+func (h *HumanCodec) normalize(s string) ([]rune, error) {
+	out := make([]rune, 0, len(s))
+	pos := 0
+	for _, r := range s {
+		if _, ok := h.inner.fromCustom[r]; ok {
+			out = append(out, r)
+			pos++
+			continue
+		}
+		if r == h.separator || r == '-' || unicode.IsSpace(r) {
+			continue
+		}
+		mapped, ok := h.mapRune(r)
+		if !ok {
+			return nil, fmt.Errorf("invalid character %q at position %d", r, pos)
+		}
+		out = append(out, mapped)
+		pos++
+	}
+	if len(out) == 0 {
+		return nil, errors.New("empty string")
+	}
+	return out, nil
+}
+
+func (h *HumanCodec) mapRune(r rune) (rune, bool) {
+	candidates := []rune{r}
+	if h.foldCase {
+		candidates = append(candidates, unicode.ToUpper(r), unicode.ToLower(r))
+	}
+	for _, c := range candidates {
+		if _, ok := h.inner.fromCustom[c]; ok {
+			return c, true
+		}
+		if to, ok := h.lookalikes[c]; ok {
+			return to, true
+		}
+	}
+	return 0, false
+}
+
+func (h *HumanCodec) group(runes []rune) string {
+	if h.groupSize == 0 {
+		return string(runes)
+	}
+	var b strings.Builder
+	for i, r := range runes {
+		if i > 0 && i%h.groupSize == 0 {
+			b.WriteRune(h.separator)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func (h *HumanCodec) digits(runes []rune) []int {
+	d := make([]int, len(runes))
+	for i, r := range runes {
+		d[i] = h.inner.fromCustom[r]
+	}
+	return d
+}
+
+// luhnSum 为校验用的加权和：从右向左，factor 在 first 与另一值（1/2）之间交替。
+// N 为偶数时按 Luhn mod N 折叠 factor·d，N 为奇数时取 factor·d mod N，使每个位置上的映射都是双射。
+func (h *HumanCodec) luhnSum(digits []int, factor int) int {
+	n := h.inner.base
+	sum := 0
+	for i := len(digits) - 1; i >= 0; i-- {
+		addend := factor * digits[i]
+		if n%2 == 0 {
+			addend = addend/n + addend%n
+		} else {
+			addend %= n
+		}
+		sum += addend
+		factor = 3 - factor
+	}
+	return sum
+}
+
+func (h *HumanCodec) checkDigit(digits []int) int {
+	n := h.inner.base
+	return (n - h.luhnSum(digits, 2)%n) % n
+}
+
+func (h *HumanCodec) valid(digits []int) bool {
+	return h.luhnSum(digits, 1)%h.inner.base == 0
+}
+
+// suggest 枚举单字符替换，保留能通过校验且可被 inner 解码的候选。
+func (h *HumanCodec) suggest(runes []rune) []Suggestion {
+	var out []Suggestion
+	digits := h.digits(runes)
+	for pos := range digits {
+		orig := digits[pos]
+		for d := 0; d < h.inner.base; d++ {
+			if d == orig {
+				continue
+			}
+			digits[pos] = d
+			if !h.valid(digits) {
+				continue
+			}
+			fixed := append([]rune(nil), runes...)
+			fixed[pos] = h.inner.chars[d]
+			if _, err := h.inner.Decode(string(fixed[:len(fixed)-1])); err == nil {
+				out = append(out, Suggestion{Pos: pos, Got: runes[pos], Want: fixed[pos], Code: h.group(fixed)})
+			}
+		}
+		digits[pos] = orig
+	}
+	return out
+}
+
+// filterTypos 只保留能被 IDX 层解码的修正候选；err 不是 *TypoError 时原样返回。
+func (m *IdMix) filterTypos(err error) error {
+	var te *TypoError
+	if !errors.As(err, &te) {
+		return err
+	}
+	kept := te.Suggestions[:0]
+	for _, sg := range te.Suggestions {
+		data, derr := m.codec.Decode(sg.Code)
+		if derr != nil {
+			continue
+		}
+		if _, derr := m.idx.Decode(data); derr == nil {
+			kept = append(kept, sg)
+		}
+	}
+	return &TypoError{Suggestions: kept}
+}
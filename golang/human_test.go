@@ -0,0 +1,239 @@
+// human_test.go 覆盖 HumanCodec：分组输出、输入归一化、单字符错误检出与修正建议。
+package idmix
+
+import (
+	"errors"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func newCrockfordHuman(t *testing.T, opts ...HumanOption) (*HumanCodec, *IdMix) {
+	t.Helper()
+	rc, err := NewRadixCodec(AlphabetCrockford32)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h, err := NewHumanCodec(rc, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := New(WithCodec(h))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return h, m
+}
+
+func TestHumanCodecRoundTrip(t *testing.T) {
+	_, m := newCrockfordHuman(t)
+	vals := []any{uint32(1001), uint16(42)}
+	s, err := m.EncodeWithVariant(5, vals...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, g := range strings.Split(s, "-") {
+		if len(g) > 4 || (len(g) != 4 && i != strings.Count(s, "-")) {
+			t.Fatalf("bad grouping %q", s)
+		}
+	}
+	t.Logf("%v => %s", vals, s)
+
+	plain := strings.ReplaceAll(s, "-", "")
+	variants := map[string]string{
+		"canonical":  s,
+		"lowercase":  strings.ToLower(s),
+		"no_dashes":  plain,
+		"spaces":     strings.ReplaceAll(s, "-", " "),
+		"lookalikes": strings.NewReplacer("0", "O", "1", "l").Replace(strings.ToLower(plain)),
+		"padded":     "  " + s + "\n",
+	}
+	for name, in := range variants {
+		list, err := m.Decode(in)
+		if err != nil || !reflect.DeepEqual(list, vals) {
+			t.Fatalf("%s %q: %v %v", name, in, list, err)
+		}
+	}
+
+	h, _ := newCrockfordHuman(t, WithGroupSize(0))
+	out, _ := h.Encode([]byte{1, 2, 3})
+	if strings.ContainsRune(out, '-') {
+		t.Fatalf("group size 0 should not group: %q", out)
+	}
+	h, _ = newCrockfordHuman(t, WithGroupSize(3), WithSeparator(' '))
+	out, _ = h.Encode([]byte{1, 2, 3})
+	if norm, _ := h.Normalize(strings.ReplaceAll(out, " ", "")); norm != out {
+		t.Fatalf("Normalize %q, want %q", norm, out)
+	}
+	t.Logf("group 3 / space: %q", out)
+}
+
+// TestHumanCodecSingleTypo 任意单字符替换都被检出，且修正建议包含原串。
+func TestHumanCodecSingleTypo(t *testing.T) {
+	h, m := newCrockfordHuman(t)
+	t.Run("xor", func(t *testing.T) { checkHumanSingleTypo(t, h, m) })
+	idx, _ := NewIdx(WithChecksum(ChecksumCRC8))
+	crc, _ := New(WithIdx(idx), WithCodec(h))
+	t.Run("crc8", func(t *testing.T) { checkHumanSingleTypo(t, h, crc) })
+}
+
+func checkHumanSingleTypo(t *testing.T, h *HumanCodec, m *IdMix) {
+	alphabet := []rune(AlphabetCrockford32)
+	codecOnly := 0
+	r := rand.New(rand.NewSource(3))
+	suggestions, checked := 0, 0
+	for i := 0; i < 100; i++ {
+		s, _ := m.Encode(r.Uint64())
+		runes := []rune(strings.ReplaceAll(s, "-", ""))
+		for pos := range runes {
+			typo := append([]rune(nil), runes...)
+			for typo[pos] == runes[pos] {
+				typo[pos] = alphabet[r.Intn(len(alphabet))]
+			}
+			if _, err := h.Decode(string(typo)); err != nil {
+				var te *TypoError
+				if errors.As(err, &te) {
+					codecOnly += len(te.Suggestions)
+				}
+			}
+			_, err := m.Decode(string(typo))
+			var te *TypoError
+			if !errors.As(err, &te) || !errors.Is(err, ErrCheckCharacter) {
+				t.Fatalf("%q (from %q): expected TypoError, got %v", string(typo), s, err)
+			}
+			found := false
+			for _, sg := range te.Suggestions {
+				if sg.Code == s {
+					found = sg.Pos == pos && sg.Want == runes[pos]
+				}
+			}
+			if !found {
+				t.Fatalf("%q: suggestions %+v do not include %q", string(typo), te.Suggestions, s)
+			}
+			suggestions += len(te.Suggestions)
+			checked++
+		}
+	}
+	t.Logf("single substitutions: %d/%d detected; suggestions on average: %.2f (codec), %.2f (after IDX filtering)",
+		checked, checked, float64(codecOnly)/float64(checked), float64(suggestions)/float64(checked))
+
+	s, _ := m.EncodeWithVariant(0, uint32(7))
+	runes := []rune(s)
+	runes[0] = alphabet[(strings.IndexRune(AlphabetCrockford32, runes[0])+1)%32]
+	_, err := h.Decode(string(runes))
+	t.Logf("codec: %q => %v", string(runes), err)
+	_, err = m.Decode(string(runes))
+	t.Logf("%q => %v", string(runes), err)
+}
+
+// TestHumanCodecTransposition 统计相邻字符对调的检出率（Luhn mod N 仅漏检个别组合）。
+func TestHumanCodecTransposition(t *testing.T) {
+	h, m := newCrockfordHuman(t)
+	r := rand.New(rand.NewSource(4))
+	total, detected := 0, 0
+	for i := 0; i < 500; i++ {
+		s, _ := m.Encode(r.Uint32(), uint8(r.Intn(200)))
+		runes := []rune(strings.ReplaceAll(s, "-", ""))
+		for pos := 0; pos+1 < len(runes); pos++ {
+			if runes[pos] == runes[pos+1] {
+				continue
+			}
+			swapped := append([]rune(nil), runes...)
+			swapped[pos], swapped[pos+1] = swapped[pos+1], swapped[pos]
+			total++
+			if _, err := h.Decode(string(swapped)); errors.Is(err, ErrCheckCharacter) {
+				detected++
+			}
+		}
+	}
+	rate := float64(detected) / float64(total)
+	t.Logf("adjacent transpositions: %d/%d detected by check character (%.2f%%)", detected, total, rate*100)
+	if rate < 0.95 {
+		t.Fatalf("transposition detection %.4f", rate)
+	}
+}
+
+func TestHumanCodecOptions(t *testing.T) {
+	rc62 := defaultCodecInstance().(*RadixCodec)
+	h, err := NewHumanCodec(rc62)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 62 字符表区分大小写，不折叠；也没有可用的易混淆映射
+	s, _ := h.Encode([]byte{0xAB, 0xCD})
+	if _, err := h.Decode(s); err != nil {
+		t.Fatal(err)
+	}
+	if h.foldCase || len(h.lookalikes) != 0 {
+		t.Fatalf("62 alphabet: foldCase=%v lookalikes=%v", h.foldCase, h.lookalikes)
+	}
+	if _, err := h.Decode(s + "!"); err == nil {
+		t.Fatal("expected invalid character error")
+	}
+
+	rc, _ := NewRadixCodec(AlphabetCrockford32)
+	bad := []HumanOption{
+		WithGroupSize(-1),
+		WithSeparator('A'),
+		WithLookalikes(map[rune]rune{'A': '0'}),
+		WithLookalikes(map[rune]rune{'U': 'u'}),
+	}
+	for i, opt := range bad {
+		if _, err := NewHumanCodec(rc, opt); err == nil {
+			t.Fatalf("option %d: expected error", i)
+		}
+	}
+	h, _ = NewHumanCodec(rc, WithLookalikes(nil))
+	out, _ := h.Encode([]byte{0})
+	if _, err := h.Decode(strings.ReplaceAll(out, "0", "O")); strings.Contains(out, "0") && err == nil {
+		t.Fatal("lookalikes disabled but O accepted")
+	}
+	if _, err := NewHumanCodec(nil); err == nil {
+		t.Fatal("expected nil codec error")
+	}
+}
+
+// TestHumanCodecAllSubstitutions 穷举每个位置的全部单字符替换，偶数与奇数长度的字符表都须全部检出。
+func TestHumanCodecAllSubstitutions(t *testing.T) {
+	for _, alphabet := range []string{AlphabetCrockford32, DefaultAlphabet, AlphabetNoLookalike55, "23456789ABCDEFGHJKMNPQRSTUVWXYZ", "123456789"} {
+		rc, err := NewRadixCodec(alphabet)
+		if err != nil {
+			t.Fatal(err)
+		}
+		h, err := NewHumanCodec(rc, WithGroupSize(0))
+		if err != nil {
+			t.Fatal(err)
+		}
+		chars := []rune(alphabet)
+		r := rand.New(rand.NewSource(5))
+		total, missed := 0, 0
+		for i := 0; i < 100; i++ {
+			data := make([]byte, 1+r.Intn(8))
+			r.Read(data)
+			s, err := h.Encode(data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			digits := h.digits([]rune(s))
+			for pos := range digits {
+				orig := digits[pos]
+				for d := range chars {
+					if d == orig {
+						continue
+					}
+					digits[pos] = d
+					total++
+					if h.valid(digits) {
+						missed++
+					}
+				}
+				digits[pos] = orig
+			}
+		}
+		t.Logf("base %d: %d/%d single substitutions detected", len(chars), total-missed, total)
+		if missed > 0 {
+			t.Fatalf("base %d: %d substitutions pass the check", len(chars), missed)
+		}
+	}
+}
@@ -11,7 +11,8 @@ package idmix
 
 import (
 	"errors"
-	"math/rand"
+	"fmt"
+	"time"
 )
 
 // DefaultAlphabet 为默认 RadixCodec 使用的 62 进制字符表。
@@ -19,8 +20,18 @@ const DefaultAlphabet = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ012
 
 // IdMix 组合 IDX 二进制编解码与文本 Codec。
 type IdMix struct {
-	idx   *Idx
-	codec Codec
+	idx    *Idx
+	codec  Codec
+	now    func() time.Time // EncodeWithTTL 时钟，nil 时为 time.Now
+	skew   time.Duration    // DecodeValid 容忍的时钟偏差
+	kinds  *kindRegistry    // WithKinds 声明的 kind，ForKind 派生实例共享（只读），见 kind.go
+	limits *DecodeLimits    // 解码资源上限，nil 为不限（见 limits.go）
+	strict bool             // 只接受规范形式（见 strict.go）
+
+	canonicalVariant int      // Canonical 输出的 variant（见 canonical.go）
+	intn             intnFunc // 选取 variant 的随机来源，nil 为默认无锁来源（见 random.go）
+	observer         Observer // 编解码观测钩子，nil 为不观测（见 observer.go）
+	kindNames        []string // WithKinds 的参数，New 时构造 kinds
 }
 
 // Option 配置 IdMix 实例（Codec、Idx）。
@@ -38,9 +49,10 @@ func WithCodec(codec Codec) Option {
 }
 
 // WithAlphabet 使用指定字符表创建 RadixCodec 并设为 IdMix 的 Codec（便捷方法）。
-func WithAlphabet(alphabet string) Option {
+// checks 为可选的字符表校验，如 WithAlphabet(a, StrictURLSafe, NoConfusables)。
+func WithAlphabet(alphabet string, checks ...AlphabetCheck) Option {
 	return func(m *IdMix) error {
-		rc, err := NewRadixCodec(alphabet)
+		rc, err := NewRadixCodec(alphabet, checks...)
 		if err != nil {
 			return err
 		}
@@ -49,6 +61,28 @@ func WithAlphabet(alphabet string) Option {
 	}
 }
 
+// WithSecretAlphabet 以 seed 打乱当前 RadixCodec 的字符表（见 ShuffleAlphabet）。
+//
+// 作用于在它之前设置的字符表，例如 WithAlphabet(AlphabetCrockford32), WithSecretAlphabet(seed)；
+// 未设置时打乱 DefaultAlphabet。当前 Codec 不是 RadixCodec 时返回错误。
+func WithSecretAlphabet(seed []byte) Option {
+	return func(m *IdMix) error {
+		if len(seed) == 0 {
+			return errors.New("secret alphabet seed cannot be empty")
+		}
+		rc, ok := m.codec.(*RadixCodec)
+		if !ok {
+			return fmt.Errorf("secret alphabet requires a RadixCodec, got %T", m.codec)
+		}
+		shuffled, err := rc.withAlphabet(ShuffleAlphabet(rc.Alphabet(), seed))
+		if err != nil {
+			return err
+		}
+		m.codec = shuffled
+		return nil
+	}
+}
+
 // WithIdx 设置 IDX 编解码器（maxObjects、maxVariants、checkBits 等在 Idx 上配置）。
 func WithIdx(idx *Idx) Option {
 	return func(m *IdMix) error {
@@ -75,6 +109,16 @@ func New(opts ...Option) (*IdMix, error) {
 			return nil, err
 		}
 	}
+	if m.limits != nil || m.strict {
+		c := *m.idx
+		c.limits = m.limits
+		c.strict = c.strict || m.strict
+		m.idx = &c
+	}
+	m.kinds = newKindRegistry(m.idx, m.kindNames)
+	if span := m.idx.tagSpan(); m.canonicalVariant >= span {
+		return nil, fmt.Errorf("canonical variant %d out of range [0, %d)", m.canonicalVariant, span)
+	}
 	return m, nil
 }
 
@@ -89,40 +133,119 @@ func (m *IdMix) Codec() Codec {
 }
 
 // Encode 将多个整数或短字符串编码为文本（Idx 二进制 + Codec.Encode）。
+// 设置了 tagBits 时等同 EncodeTagged(0, values...)。
 func (m *IdMix) Encode(values ...any) (string, error) {
+	return m.encode(nil, values)
+}
+
+// encode 为 Encode 的实现，sc 非 nil 时复用其缓冲区（见 batch.go）。
+func (m *IdMix) encode(sc *batchScratch, values []any) (string, error) {
 	if len(values) < 1 {
-		return "", errors.New("at least one value is required")
+		return m.encodeRejected(errors.New("at least one value is required"))
 	}
-	variantID := rand.Intn(m.idx.maxVariants)
-	data, err := m.encodeBinary(values, variantID)
+	variantID, err := m.idx.taggedVariant(0, m.randIntn)
 	if err != nil {
-		return "", err
+		return m.encodeRejected(err)
 	}
-	return m.codec.Encode(data)
+	return m.encodeVariant(sc, variantID, values, false)
+}
+
+// encodeVariant 以指定 variant_id 编码（编码入口共用），设置了 Observer 时上报结果。
+// ttl 为 true 时 values 末尾两个为有效期元数据（见 EncodeWithTTL）；sc 非 nil 时复用其缓冲区（见 batch.go）。
+func (m *IdMix) encodeVariant(sc *batchScratch, variantID int, values []any, ttl bool) (string, error) {
+	var start time.Time
+	if m.observer != nil {
+		start = time.Now()
+	}
+	data, err := m.appendBlock(sc, values, variantID, ttl)
+	var s string
+	if err == nil {
+		s, err = m.encodeText(sc, data)
+	}
+	if m.observer != nil {
+		e := Event{Op: OpEncode, Duration: time.Since(start), TextLen: len(s), BinaryLen: len(data), Variant: variantID, Err: err}
+		if err != nil {
+			e.Class = ErrClassInvalidInput
+		}
+		m.observer.Observe(e)
+	}
+	return s, err
 }
 
 func (m *IdMix) encodeBinary(values []any, variantID int) ([]byte, error) {
+	return m.encodeBlock(values, variantID, false)
+}
+
+func (m *IdMix) encodeBlock(values []any, variantID int, ttl bool) ([]byte, error) {
 	objects, err := normalizeObjects(values)
 	if err != nil {
 		return nil, err
 	}
-	return m.idx.encodeBinary(objects, variantID)
+	return m.idx.encodeBlock(objects, variantID, ttl)
 }
 
-// Decode 将文本解码为 []any。
+// appendBlock 同 encodeBlock，sc 非 nil 时复用其对象与块缓冲区，返回的块在下次使用 sc 前有效。
+func (m *IdMix) appendBlock(sc *batchScratch, values []any, variantID int, ttl bool) ([]byte, error) {
+	if sc == nil {
+		return m.encodeBlock(values, variantID, ttl)
+	}
+	objects, err := appendObjects(sc.objects[:0], values)
+	if err != nil {
+		return nil, err
+	}
+	sc.objects = objects
+	data, err := m.idx.appendBlock(sc.block[:0], objects, variantID, ttl)
+	if err != nil {
+		return nil, err
+	}
+	sc.block = data
+	return data, nil
+}
+
+// encodeText 以 Codec 编码二进制块；标准 RadixCodec 且 sc 非 nil 时复用 sc 的大整数与缓冲区。
+func (m *IdMix) encodeText(sc *batchScratch, data []byte) (string, error) {
+	if rc, ok := m.codec.(*RadixCodec); ok && sc != nil {
+		return rc.encode(&sc.radix, data)
+	}
+	return m.codec.Encode(data)
+}
+
+// Decode 将文本解码为 []any。EncodeWithTTL 的输出包含末尾两个有效期元数据值，且不校验有效期（见 DecodeValid）。
 func (m *IdMix) Decode(s string) ([]any, error) {
-	data, err := m.codec.Decode(s)
+	_, values, err := m.decodeObserved(nil, OpDecode, s)
+	return values, err
+}
+
+// Inspection 为 Inspect 的结果：文本层还原的二进制块、variant_id、格式版本与解码值。
+type Inspection struct {
+	Binary    []byte
+	VariantID int
+	// Version 为块的 IDX 格式版本；VersionMarked 报告块中是否带版本标记（未带时为 IdxV12）。
+	Version       IdxVersion
+	VersionMarked bool
+	// TTL 报告块是否带有效期标记，此时 Values 末尾两个为签发时间与有效期（见 EncodeWithTTL）。
+	TTL    bool
+	Values []any
+}
+
+// Inspect 解码文本并返回中间结果，用于排障与跨语言比对。
+func (m *IdMix) Inspect(s string) (*Inspection, error) {
+	data, values, err := m.decodeObserved(nil, OpInspect, s)
+	if err != nil {
+		return nil, err
+	}
+	variantID, err := m.idx.VariantOf(data)
 	if err != nil {
 		return nil, err
 	}
-	return m.idx.Decode(data)
+	version, marked, err := m.idx.VersionOf(data)
+	if err != nil {
+		return nil, err
+	}
+	return &Inspection{Binary: data, VariantID: variantID, Version: version, VersionMarked: marked, TTL: m.idx.hasTTL(data), Values: values}, nil
 }
 
 // EncodeWithVariant 确定性编码（指定 variant_id），主要用于测试。
 func (m *IdMix) EncodeWithVariant(variantID int, values ...any) (string, error) {
-	data, err := m.encodeBinary(values, variantID)
-	if err != nil {
-		return "", err
-	}
-	return m.codec.Encode(data)
+	return m.encodeVariant(nil, variantID, values, false)
 }
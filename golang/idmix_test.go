@@ -584,3 +584,23 @@ func TestRejectRateApprox(t *testing.T) {
 		t.Fatalf("pass rate %d%% too high", rate)
 	}
 }
+
+// TestInspect 返回文本层还原的二进制块与 variant_id。
+func TestInspect(t *testing.T) {
+	m, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	str, err := m.EncodeWithVariant(7, uint16(5), int64(-1), uint32(40))
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err := m.Inspect(str)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.VariantID != 7 || len(info.Values) != 3 || info.Values[2].(uint32) != 40 {
+		t.Fatalf("unexpected inspection %+v", info)
+	}
+	t.Logf("Inspect(%q): binary=%s variant=%d values=%v", str, formatHex(info.Binary), info.VariantID, info.Values)
+}
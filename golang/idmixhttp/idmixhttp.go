@@ -0,0 +1,260 @@
+// Package idmixhttp 为 net/http 提供 idmix 参数解码中间件与辅助函数。
+//
+// 典型用法（Go 1.22+ ServeMux 路由模式）：
+//
+//	params := []idmixhttp.Param{idmixhttp.Path("id", uint32(0))}
+//	mux.Handle("GET /users/{id}", idmixhttp.Middleware(m, params)(http.HandlerFunc(h)))
+//
+//	func h(w http.ResponseWriter, r *http.Request) {
+//	    id, _ := idmixhttp.Value[uint32](r, "id")
+//	    ...
+//	}
+//
+// 解码失败时中间件直接写出错误响应：路径参数 404（资源不存在），
+// 查询参数 400（请求非法）；可通过 WithErrorHandler 自定义。
+package idmixhttp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+
+	idmix "github.com/Vanni-Fan/idmix/golang"
+)
+
+// Source 表示参数来源。
+type Source int
+
+const (
+	// InPath 取自 ServeMux 路由通配符（r.PathValue）。
+	InPath Source = iota
+	// InQuery 取自 URL 查询串（r.URL.Query().Get）。
+	InQuery
+)
+
+func (s Source) String() string {
+	switch s {
+	case InPath:
+		return "path"
+	case InQuery:
+		return "query"
+	default:
+		return fmt.Sprintf("Source(%d)", int(s))
+	}
+}
+
+// Param 描述一个需要解码的请求参数。
+type Param struct {
+	Name string
+	In   Source
+	// Want 为期望的值原型（如 uint32(0)、""），按位置比对个数与 Go 类型；为空时不校验。
+	Want []any
+	// Optional 为 true 时参数缺失不报错，上下文中不写入该参数。
+	Optional bool
+}
+
+// Path 声明路径参数，want 为期望的值原型（按解码规则归一化，见 normalizeWant）。
+func Path(name string, want ...any) Param {
+	return Param{Name: name, In: InPath, Want: normalizeWants(want)}
+}
+
+// Query 声明查询参数，want 为期望的值原型（按解码规则归一化，见 normalizeWant）。
+func Query(name string, want ...any) Param {
+	return Param{Name: name, In: InQuery, Want: normalizeWants(want)}
+}
+
+var (
+	// ErrMissing 参数缺失。
+	ErrMissing = errors.New("parameter is missing")
+	// ErrShape 解码成功但值的个数或类型与 Param.Want 不符。
+	ErrShape = errors.New("decoded values do not match expected types")
+)
+
+// DecodeError 描述单个参数的解码失败，Status 为建议的 HTTP 状态码。
+type DecodeError struct {
+	Param  Param
+	Status int
+	Err    error
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("%s parameter %q: %v", e.Param.In, e.Param.Name, e.Err)
+}
+
+func (e *DecodeError) Unwrap() error { return e.Err }
+
+// ErrorHandler 在解码失败时写出响应。
+type ErrorHandler func(w http.ResponseWriter, r *http.Request, err *DecodeError)
+
+// DefaultErrorHandler 以 http.Error 写出状态码与错误说明（不回显原始参数值）。
+func DefaultErrorHandler(w http.ResponseWriter, r *http.Request, err *DecodeError) {
+	http.Error(w, fmt.Sprintf("invalid %s parameter %q", err.Param.In, err.Param.Name), err.Status)
+}
+
+type config struct {
+	onError ErrorHandler
+}
+
+// Option 配置中间件。
+type Option func(*config)
+
+// WithErrorHandler 自定义解码失败时的响应。
+func WithErrorHandler(h ErrorHandler) Option {
+	return func(c *config) {
+		if h != nil {
+			c.onError = h
+		}
+	}
+}
+
+// Middleware 返回 net/http 中间件：按 params 解码参数并写入请求上下文。
+//
+// 任一参数失败时调用 ErrorHandler 并终止请求，不再调用下游 Handler。
+func Middleware(m *idmix.IdMix, params []Param, opts ...Option) func(http.Handler) http.Handler {
+	cfg := config{onError: DefaultErrorHandler}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			decoded := make(map[string][]any, len(params))
+			for _, p := range params {
+				values, err := DecodeParam(m, r, p)
+				if err != nil {
+					if p.Optional && errors.Is(err, ErrMissing) {
+						continue
+					}
+					var de *DecodeError
+					if !errors.As(err, &de) {
+						de = &DecodeError{Param: p, Status: http.StatusInternalServerError, Err: err}
+					}
+					cfg.onError(w, r, de)
+					return
+				}
+				decoded[p.Name] = values
+			}
+			next.ServeHTTP(w, r.WithContext(withValues(r.Context(), decoded)))
+		})
+	}
+}
+
+// DecodeParam 读取并解码单个参数，失败时返回 *DecodeError。
+func DecodeParam(m *idmix.IdMix, r *http.Request, p Param) ([]any, error) {
+	var raw string
+	switch p.In {
+	case InPath:
+		raw = r.PathValue(p.Name)
+	case InQuery:
+		raw = r.URL.Query().Get(p.Name)
+	default:
+		return nil, &DecodeError{Param: p, Status: http.StatusInternalServerError, Err: fmt.Errorf("unknown source %d", p.In)}
+	}
+	if raw == "" {
+		return nil, &DecodeError{Param: p, Status: failStatus(p), Err: ErrMissing}
+	}
+	values, err := m.Decode(raw)
+	if err != nil {
+		return nil, &DecodeError{Param: p, Status: failStatus(p), Err: err}
+	}
+	if err := checkShape(values, p.Want); err != nil {
+		return nil, &DecodeError{Param: p, Status: failStatus(p), Err: err}
+	}
+	return values, nil
+}
+
+// failStatus 路径参数标识资源，解不开即视为不存在（404）；查询参数视为请求非法（400）。
+func failStatus(p Param) int {
+	if p.In == InPath {
+		return http.StatusNotFound
+	}
+	return http.StatusBadRequest
+}
+
+func checkShape(values []any, want []any) error {
+	if len(want) == 0 {
+		return nil
+	}
+	if len(values) != len(want) {
+		return fmt.Errorf("%w: got %d values, want %d", ErrShape, len(values), len(want))
+	}
+	for i := range want {
+		if wt := reflect.TypeOf(normalizeWant(want[i])); reflect.TypeOf(values[i]) != wt {
+			return fmt.Errorf("%w: value[%d] is %T, want %v", ErrShape, i, values[i], wt)
+		}
+	}
+	return nil
+}
+
+func normalizeWants(want []any) []any {
+	if len(want) == 0 {
+		return nil
+	}
+	out := make([]any, len(want))
+	for i, v := range want {
+		out[i] = normalizeWant(v)
+	}
+	return out
+}
+
+// normalizeWant 与 idmix 解码规则一致：int/uint 按 int64/uint64 还原，[]byte 按 string 还原。
+func normalizeWant(v any) any {
+	switch v.(type) {
+	case int:
+		return int64(0)
+	case uint:
+		return uint64(0)
+	case []byte:
+		return ""
+	default:
+		return v
+	}
+}
+
+type ctxKey struct{}
+
+func withValues(ctx context.Context, decoded map[string][]any) context.Context {
+	if prev, ok := ctx.Value(ctxKey{}).(map[string][]any); ok {
+		merged := make(map[string][]any, len(prev)+len(decoded))
+		for k, v := range prev {
+			merged[k] = v
+		}
+		for k, v := range decoded {
+			merged[k] = v
+		}
+		decoded = merged
+	}
+	return context.WithValue(ctx, ctxKey{}, decoded)
+}
+
+// Values 返回中间件写入上下文的全部解码值。
+func Values(r *http.Request, name string) ([]any, bool) {
+	return ValuesFromContext(r.Context(), name)
+}
+
+// ValuesFromContext 同 Values，直接读取 context。
+func ValuesFromContext(ctx context.Context, name string) ([]any, bool) {
+	decoded, ok := ctx.Value(ctxKey{}).(map[string][]any)
+	if !ok {
+		return nil, false
+	}
+	v, ok := decoded[name]
+	return v, ok
+}
+
+// Value 返回参数解码后的第一个值并断言为 T。
+func Value[T any](r *http.Request, name string) (T, bool) {
+	return ValueAt[T](r, name, 0)
+}
+
+// ValueAt 返回参数解码后第 i 个值并断言为 T。
+func ValueAt[T any](r *http.Request, name string, i int) (T, bool) {
+	var zero T
+	values, ok := Values(r, name)
+	if !ok || i < 0 || i >= len(values) {
+		return zero, false
+	}
+	v, ok := values[i].(T)
+	return v, ok
+}
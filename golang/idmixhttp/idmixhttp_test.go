@@ -0,0 +1,149 @@
+// idmixhttp_test.go 覆盖路径/查询参数解码、类型校验与错误响应。
+package idmixhttp
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	idmix "github.com/Vanni-Fan/idmix/golang"
+)
+
+func newMux(t *testing.T, m *idmix.IdMix, opts ...Option) *http.ServeMux {
+	t.Helper()
+	mux := http.NewServeMux()
+	params := []Param{Path("id", uint32(0)), {Name: "ref", In: InQuery, Want: []any{uint16(0), ""}, Optional: true}}
+	mux.Handle("GET /users/{id}", Middleware(m, params, opts...)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, ok := Value[uint32](r, "id")
+		if !ok {
+			t.Error("id missing from context")
+		}
+		out := fmt.Sprintf("id=%d", id)
+		if ref, ok := Values(r, "ref"); ok {
+			out += fmt.Sprintf(" ref=%v", ref)
+		}
+		fmt.Fprint(w, out)
+	})))
+	return mux
+}
+
+func serve(mux http.Handler, target string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+	return rec
+}
+
+func TestMiddlewareDecodes(t *testing.T) {
+	m, err := idmix.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	mux := newMux(t, m)
+
+	id, _ := m.Encode(uint32(42))
+	rec := serve(mux, "/users/"+id)
+	if rec.Code != http.StatusOK || rec.Body.String() != "id=42" {
+		t.Fatalf("got %d %q", rec.Code, rec.Body.String())
+	}
+
+	ref, _ := m.Encode(uint16(7), "web")
+	rec = serve(mux, "/users/"+id+"?ref="+ref)
+	if rec.Code != http.StatusOK || rec.Body.String() != "id=42 ref=[7 web]" {
+		t.Fatalf("got %d %q", rec.Code, rec.Body.String())
+	}
+	t.Logf("GET /users/%s?ref=%s => %q", id, ref, rec.Body.String())
+}
+
+func TestMiddlewareErrors(t *testing.T) {
+	m, err := idmix.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	mux := newMux(t, m)
+
+	wrongType, _ := m.Encode(uint64(42))
+	goodID, _ := m.Encode(uint32(1))
+	badRef, _ := m.Encode(uint32(1))
+	cases := []struct {
+		name   string
+		target string
+		status int
+	}{
+		{"garbage_path", "/users/!!!", http.StatusNotFound},
+		{"wrong_type_path", "/users/" + wrongType, http.StatusNotFound},
+		{"wrong_shape_query", "/users/" + goodID + "?ref=" + badRef, http.StatusBadRequest},
+		{"garbage_query", "/users/" + goodID + "?ref=~", http.StatusBadRequest},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			rec := serve(mux, c.target)
+			if rec.Code != c.status {
+				t.Fatalf("status %d, want %d (%q)", rec.Code, c.status, rec.Body.String())
+			}
+			t.Logf("%s => %d %q", c.target, rec.Code, rec.Body.String())
+		})
+	}
+}
+
+func TestMiddlewareCustomErrorHandler(t *testing.T) {
+	m, err := idmix.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got *DecodeError
+	mux := newMux(t, m, WithErrorHandler(func(w http.ResponseWriter, r *http.Request, err *DecodeError) {
+		got = err
+		w.WriteHeader(http.StatusTeapot)
+	}))
+	other, _ := m.Encode("x")
+	rec := serve(mux, "/users/"+other)
+	if rec.Code != http.StatusTeapot {
+		t.Fatalf("status %d", rec.Code)
+	}
+	if got == nil || got.Param.Name != "id" || !errors.Is(got, ErrShape) {
+		t.Fatalf("unexpected error %v", got)
+	}
+}
+
+func TestDecodeParamMissing(t *testing.T) {
+	m, err := idmix.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	_, err = DecodeParam(m, r, Query("q"))
+	var de *DecodeError
+	if !errors.As(err, &de) || !errors.Is(err, ErrMissing) || de.Status != http.StatusBadRequest {
+		t.Fatalf("got %v", err)
+	}
+	if _, ok := Value[uint32](r, "q"); ok {
+		t.Fatal("value should be absent without middleware")
+	}
+}
+
+// TestWantNormalized int、uint、[]byte 原型按解码后的类型比对。
+func TestWantNormalized(t *testing.T) {
+	m, err := idmix.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := Query("q", int(0), uint(0), []byte(nil))
+	if _, ok := p.Want[0].(int64); !ok {
+		t.Fatalf("want = %#v", p.Want)
+	}
+	s, _ := m.Encode(int64(-1), uint64(2), "x")
+	for _, p := range []Param{p, {Name: "q", In: InQuery, Want: []any{int(0), uint(0), []byte(nil)}}} {
+		r := httptest.NewRequest(http.MethodGet, "/?q="+s, nil)
+		if _, err := DecodeParam(m, r, p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	r := httptest.NewRequest(http.MethodGet, "/?q="+s, nil)
+	_, err = DecodeParam(m, r, Query("q", int(0), int(0), ""))
+	if !errors.Is(err, ErrShape) {
+		t.Fatalf("got %v", err)
+	}
+	t.Logf("%v", err)
+}
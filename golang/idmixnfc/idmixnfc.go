@@ -0,0 +1,30 @@
+// Package idmixnfc 为 idmix 的 NFCStable 字符表校验提供实现（基于 golang.org/x/text）。
+//
+// 匿名导入即可启用：
+//
+//	import _ "github.com/Vanni-Fan/idmix/golang/idmixnfc"
+//
+//	rc, err := idmix.NewRadixCodec(alphabet, idmix.NFCStable)
+//
+// 未导入时 NFCStable 校验返回 idmix.ErrNFCUnavailable。
+package idmixnfc
+
+import (
+	"golang.org/x/text/unicode/norm"
+
+	idmix "github.com/Vanni-Fan/idmix/golang"
+)
+
+func init() {
+	idmix.RegisterNFCStable(Stable)
+}
+
+// Stable 报告 r 在 NFC 规范化下保持不变，且不会与前一个字符合成。
+func Stable(r rune) bool {
+	s := string(r)
+	if !norm.NFC.IsNormalString(s) {
+		return false
+	}
+	// 不以边界开头的字符（组合符号、韩文元音/收音字母等）可能与前一字符合成
+	return norm.NFC.PropertiesString(s).BoundaryBefore()
+}
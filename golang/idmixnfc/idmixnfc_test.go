@@ -0,0 +1,79 @@
+// idmixnfc_test.go 覆盖 NFCStable 校验：通过与拒绝的字符表，以及通过校验时输出在 NFC 下不变。
+package idmixnfc
+
+import (
+	"errors"
+	"testing"
+
+	"golang.org/x/text/unicode/norm"
+
+	idmix "github.com/Vanni-Fan/idmix/golang"
+)
+
+func TestNFCStableAccepts(t *testing.T) {
+	cases := []struct {
+		name     string
+		alphabet string
+		checks   []idmix.AlphabetCheck
+	}{
+		{"default_url_nfc", idmix.DefaultAlphabet, []idmix.AlphabetCheck{idmix.StrictURLSafe, idmix.NFCStable}},
+		{"url64_all_but_confusables", idmix.AlphabetURLSafe64, []idmix.AlphabetCheck{idmix.StrictURLSafe | idmix.NFCStable}},
+		{"no_lookalike_all", idmix.AlphabetNoLookalike55, []idmix.AlphabetCheck{idmix.StrictURLSafe, idmix.NoConfusables, idmix.NFCStable}},
+		{"crockford_all", idmix.AlphabetCrockford32, []idmix.AlphabetCheck{idmix.StrictURLSafe, idmix.NoConfusables, idmix.NFCStable}},
+		{"chinese_nfc", "一二三四五六七八九十", []idmix.AlphabetCheck{idmix.NoConfusables, idmix.NFCStable}},
+		{"precomposed_nfc", "aéöñ", []idmix.AlphabetCheck{idmix.NFCStable}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if err := idmix.ValidateAlphabet(c.alphabet, c.checks...); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestNFCStableRejects(t *testing.T) {
+	cases := []struct {
+		name     string
+		alphabet string
+		chars    string
+	}{
+		{"decomposed_mark", "e\u0301x", "\u0301"},
+		{"kelvin_sign", "J\u212a", "\u212a"},
+		{"angstrom_sign", "B\u212b", "\u212b"},
+		{"hangul_vowel_jamo", "\u1100\u1161", "\u1161"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := idmix.ValidateAlphabet(c.alphabet, idmix.NFCStable)
+			var ae *idmix.AlphabetError
+			if !errors.As(err, &ae) || ae.Check != idmix.NFCStable {
+				t.Fatalf("got %v", err)
+			}
+			if string(ae.Chars) != c.chars {
+				t.Fatalf("offending chars %q, want %q", string(ae.Chars), c.chars)
+			}
+			t.Logf("%q => %v", c.alphabet, err)
+		})
+	}
+}
+
+// TestNFCStableOutput 通过 NFCStable 的字符表，其编码输出经 NFC 规范化后不变；Profile 中的 nfcStable 同样生效。
+func TestNFCStableOutput(t *testing.T) {
+	m, err := idmix.New(idmix.WithAlphabet("一二三四五六七八九十aéöñ", idmix.NFCStable))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 200; i++ {
+		s, err := m.Encode(uint32(i*7919), "k")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if norm.NFC.String(s) != s {
+			t.Fatalf("%q changes under NFC", s)
+		}
+	}
+	if err := (idmix.Profile{Alphabet: "JK", AlphabetChecks: []string{"nfcStable"}}).Validate(); err == nil {
+		t.Fatal("profile nfcStable should reject U+212A")
+	}
+}
@@ -11,6 +11,7 @@
 package idmix
 
 import (
+	"crypto/sha256"
 	"errors"
 	"fmt"
 	"math"
@@ -46,10 +47,17 @@ type dataObject struct {
 
 // Idx 是 IDX 二进制编解码器，可独立于 idmix 文本层使用。
 type Idx struct {
-	maxObjects  int
-	maxVariants int
-	checkBits   int
-	checkMask   uint8
+	maxObjects   int
+	maxVariants  int
+	checkBits    int
+	checkMask    uint8
+	tagBits      int           // variant_id 中 tag 所占位数（见 tag.go）
+	checksumMode ChecksumMode  // 编码使用的校验方式（见 checksum.go）
+	version      IdxVersion    // 编码写入的版本标记（见 version.go）
+	limits       *DecodeLimits // 解码资源上限（见 limits.go），nil 为不限
+	strict       bool          // 只接受规范形式（见 strict.go）
+	kind         string        // 实体类别（见 ForKind），空为不区分
+	kindSalt     []byte
 }
 
 // IdxOption 配置 Idx 实例。
@@ -102,17 +110,26 @@ func NewIdx(opts ...IdxOption) (*Idx, error) {
 			return nil, err
 		}
 	}
+	if err := validateTagSplit(idx.maxVariants, idx.tagBits); err != nil {
+		return nil, err
+	}
 	return idx, nil
 }
 
+// MaxObjects 返回单次编码允许的最大对象个数。
+func (idx *Idx) MaxObjects() int { return idx.maxObjects }
+
+// MaxVariants 返回变体数。
+func (idx *Idx) MaxVariants() int { return idx.maxVariants }
+
+// CheckBits 返回 header 校验位宽度。
+func (idx *Idx) CheckBits() int { return idx.checkBits }
+
 // Encode 将多个整数或短字符串（≤63 字节）编码为 IDX 二进制块。
 func (idx *Idx) Encode(values ...any) ([]byte, error) {
 	if len(values) < 1 {
 		return nil, errors.New("at least one value is required")
 	}
-	if len(values) > idx.maxObjects {
-		return nil, fmt.Errorf("too many objects: %d (max %d)", len(values), idx.maxObjects)
-	}
 	objects, err := normalizeObjects(values)
 	if err != nil {
 		return nil, err
@@ -125,9 +142,6 @@ func (idx *Idx) EncodeWithVariant(variantID int, values ...any) ([]byte, error)
 	if len(values) < 1 {
 		return nil, errors.New("at least one value is required")
 	}
-	if len(values) > idx.maxObjects {
-		return nil, fmt.Errorf("too many objects: %d (max %d)", len(values), idx.maxObjects)
-	}
 	objects, err := normalizeObjects(values)
 	if err != nil {
 		return nil, err
@@ -144,130 +158,237 @@ func (idx *Idx) Decode(data []byte) ([]any, error) {
 	return materializeObjects(objects)
 }
 
+// VariantOf 读取二进制块 header 中的 variant_id（不做校验和与对象解析）。
+func (idx *Idx) VariantOf(data []byte) (int, error) {
+	if len(data) < 1 {
+		return 0, errors.New("invalid data: too short")
+	}
+	return int((data[0] & 0x7F) >> idx.checkBits), nil
+}
+
 func (idx *Idx) encodeBinary(objects []dataObject, variantID int) ([]byte, error) {
+	return idx.encodeBlock(objects, variantID, false)
+}
+
+// encodeBlock 编码对象序列；ttl 为 true 时在扩展头中标记末尾两个对象为有效期元数据。
+func (idx *Idx) encodeBlock(objects []dataObject, variantID int, ttl bool) ([]byte, error) {
+	return idx.appendBlock(nil, objects, variantID, ttl)
+}
+
+// appendBlock 同 encodeBlock，将块追加到 dst 后返回（批量编码复用 dst，见 batch.go）。
+func (idx *Idx) appendBlock(dst []byte, objects []dataObject, variantID int, ttl bool) ([]byte, error) {
 	if variantID < 0 || variantID >= idx.maxVariants {
 		return nil, fmt.Errorf("invalid variant_id %d (max %d)", variantID, idx.maxVariants-1)
 	}
+	if len(objects) > idx.maxObjects {
+		return nil, fmt.Errorf("too many objects: %d (max %d)", len(objects), idx.maxObjects)
+	}
 
-	objBytes := make([]byte, 0, len(objects)*2)
+	start := len(dst)
 	for _, obj := range objects {
-		ob, err := encodeObject(obj)
-		if err != nil {
+		var err error
+		if dst, err = appendObject(dst, obj); err != nil {
 			return nil, err
 		}
-		objBytes = append(objBytes, ob...)
-	}
-
-	mask := byte((variantID*0x9D + 0x37) & 0xFF)
-	for i := range objBytes {
-		objBytes[i] ^= mask
 	}
+	objBytes := dst[start:]
 
 	count := len(objects)
-	headerLen := 1
-	if count > 1 {
-		headerLen = 2
+	var buf [8]byte // 扩展头最长 7 字节
+	var header []byte
+	switch {
+	case idx.checksumMode != ChecksumXOR || idx.version != IdxVersionUnmarked || ttl || idx.kindSalt != nil:
+		header = idx.appendExtendedHeader(buf[:0], variantID, count, objBytes, ttl)
+	case count == 1:
+		header = append(buf[:0], byte(variantID<<idx.checkBits))
+	default:
+		header = append(buf[:0], 0x80|byte(variantID<<idx.checkBits), byte(count))
 	}
 
-	data := make([]byte, headerLen+len(objBytes))
-	if count == 1 {
-		data[0] = byte(variantID << idx.checkBits)
-	} else {
-		data[0] = 0x80 | byte(variantID<<idx.checkBits)
-		data[1] = byte(count)
-	}
-	copy(data[headerLen:], objBytes)
+	idx.maskObjects(objBytes, variantID)
 
-	xorSum := byte(0)
-	for _, b := range data {
-		xorSum ^= b
+	// 对象区后移，头部写在其前
+	n := len(objBytes)
+	dst = append(dst, header...)
+	copy(dst[start+len(header):], dst[start:start+n])
+	copy(dst[start:], header)
+	data := dst[start:]
+
+	if idx.kindSalt != nil {
+		sum := idx.kindSum(data)
+		data[0] |= sum[0] & idx.checkMask
+		return append(dst, sum[1]), nil
 	}
-	check := xorSum & idx.checkMask
-	data[0] |= check
-	return data, nil
+	data[0] |= idx.checksum(data)
+	return dst, nil
 }
 
-func (idx *Idx) decodeBinary(data []byte) ([]dataObject, error) {
+// idxHeader 为解析后的块头部。
+type idxHeader struct {
+	variantID int
+	check     byte
+	count     int
+	mode      ChecksumMode
+	version   IdxVersion // IdxVersionUnmarked 表示未带版本标记
+	ttl       bool       // 末尾两个对象为有效期元数据
+	kind      bool       // 块绑定了 kind，末尾带 kind 校验字节
+	headerLen int
+}
+
+// parseHeader 解析 header（含扩展头），不做校验和验证。
+func (idx *Idx) parseHeader(data []byte) (idxHeader, error) {
 	if len(data) < 1 {
-		return nil, errors.New("invalid data: too short")
+		return idxHeader{}, errors.New("invalid data: too short")
+	}
+	if err := idx.limits.checkBinary(len(data), false); err != nil {
+		return idxHeader{}, err
 	}
 
 	byte0 := data[0]
-	check := byte0 & idx.checkMask
-	multi := (byte0 & 0x80) != 0
-	variantID := int((byte0 & 0x7F) >> idx.checkBits)
-
-	if variantID >= idx.maxVariants {
-		return nil, fmt.Errorf("invalid variant_id %d (max %d)", variantID, idx.maxVariants-1)
+	h := idxHeader{
+		variantID: int((byte0 & 0x7F) >> idx.checkBits),
+		check:     byte0 & idx.checkMask,
+		count:     1,
+		mode:      ChecksumXOR,
+		headerLen: 1,
+	}
+	if h.variantID >= idx.maxVariants {
+		return idxHeader{}, fmt.Errorf("invalid variant_id %d (max %d)", h.variantID, idx.maxVariants-1)
+	}
+	if byte0&0x80 == 0 {
+		return h, nil
+	}
+	if len(data) < 2 {
+		return idxHeader{}, errors.New("invalid data: missing count byte")
+	}
+	if data[1] == extMarker {
+		return idx.parseExtendedHeader(data, h)
+	}
+	h.headerLen = 2
+	h.count = int(data[1])
+	if h.count < 2 || h.count > idx.maxObjects {
+		return idxHeader{}, fmt.Errorf("invalid count %d", h.count)
 	}
+	if err := idx.limits.checkCount(h.count); err != nil {
+		return idxHeader{}, err
+	}
+	return h, nil
+}
 
-	headerLen := 1
-	count := 1
-	if multi {
-		if len(data) < 2 {
-			return nil, errors.New("invalid data: missing count byte")
+// decodeBinary 解析头部后按块的版本选择对象解析器（见 version.go）。
+func (idx *Idx) decodeBinary(data []byte) ([]dataObject, error) {
+	h, err := idx.parseHeader(data)
+	if err != nil {
+		return nil, err
+	}
+	if h.mode < idx.checksumMode {
+		return nil, fmt.Errorf("checksum %s is weaker than required %s", h.mode, idx.checksumMode)
+	}
+	if h.kind != (idx.kindSalt != nil) {
+		if h.kind {
+			return nil, errors.New("invalid data: block is bound to a kind")
 		}
-		headerLen = 2
-		count = int(data[1])
-		if count < 2 || count > idx.maxObjects {
-			return nil, fmt.Errorf("invalid count %d", count)
+		return nil, errors.New("invalid data: block is not bound to a kind")
+	}
+	if idx.strict {
+		if err := idx.checkCanonicalHeader(h); err != nil {
+			return nil, err
 		}
 	}
 
-	verify := make([]byte, len(data))
-	copy(verify, data)
+	body := data
+	if idx.kindSalt != nil {
+		if len(data) <= h.headerLen {
+			return nil, errors.New("invalid data: missing kind check byte")
+		}
+		body = data[:len(data)-1]
+	}
+	verify := make([]byte, len(body))
+	copy(verify, body)
 	verify[0] &^= idx.checkMask
-	xorSum := byte(0)
-	for _, b := range verify {
-		xorSum ^= b
+	if idx.kindSalt != nil {
+		if sum := idx.kindSum(verify); sum[0]&idx.checkMask != h.check || sum[1] != data[len(data)-1] {
+			return nil, ErrChecksumMismatch
+		}
+	} else if idx.checksum(verify) != h.check {
+		return nil, ErrChecksumMismatch
 	}
-	if xorSum&idx.checkMask != check {
-		return nil, errors.New("checksum mismatch")
+
+	objData := make([]byte, len(body)-h.headerLen)
+	copy(objData, body[h.headerLen:])
+	idx.maskObjects(objData, h.variantID)
+	if h.mode != ChecksumXOR {
+		if err := idx.verifyCRC(data, h.mode, h.headerLen, objData); err != nil {
+			return nil, err
+		}
 	}
 
-	objData := make([]byte, len(data)-headerLen)
-	copy(objData, data[headerLen:])
-	mask := byte((variantID*0x9D + 0x37) & 0xFF)
-	for i := range objData {
-		objData[i] ^= mask
+	if h.ttl && h.count <= ttlObjects {
+		return nil, fmt.Errorf("invalid ttl block: %d objects", h.count)
+	}
+	version := h.version
+	if version == IdxVersionUnmarked {
+		version = IdxV12
 	}
+	return idxParsers[version](idx, objData, h.count)
+}
 
-	result := make([]dataObject, 0, count)
-	pos := 0
-	for i := 0; i < count; i++ {
-		if pos >= len(objData) {
-			return nil, errors.New("premature end of data")
-		}
-		obj, n, err := decodeObject(objData[pos:])
-		if err != nil {
-			return nil, fmt.Errorf("object[%d]: %w", i, err)
+// hasTTL 报告块的扩展头是否带有效期标记（不做校验和验证，用于已成功解码的块）。
+func (idx *Idx) hasTTL(data []byte) bool {
+	h, err := idx.parseHeader(data)
+	return err == nil && h.ttl
+}
+
+// maskObjects 以 variant_id 派生的掩码异或对象区（编码与解码对称）；
+// 设置了 kind 时再叠加 kind 盐的逐字节掩码（起点随 variant_id 轮转）。
+func (idx *Idx) maskObjects(objBytes []byte, variantID int) {
+	mask := byte((variantID*0x9D + 0x37) & 0xFF)
+	for i := range objBytes {
+		objBytes[i] ^= mask
+	}
+	if salt := idx.kindSalt; salt != nil {
+		for i := range objBytes {
+			objBytes[i] ^= salt[(i+variantID)%len(salt)]
 		}
-		result = append(result, obj)
-		pos += n
 	}
-	if pos != len(objData) {
-		return nil, errors.New("extra bytes after data objects")
+}
+
+// checksum 计算未设置 kind 时 header 中的 check 位（整块逐字节 XOR 的低位），data[0] 的 check 位须已清零。
+func (idx *Idx) checksum(data []byte) byte {
+	xorSum := byte(0)
+	for _, b := range data {
+		xorSum ^= b
 	}
-	return result, nil
+	return xorSum & idx.checkMask
+}
+
+// kindSum 为设置了 kind 时的校验：SHA-256(kind 盐 || data)，data 不含末尾的 kind 校验字节且 check 位已清零。
+// 首字节的低 checkBits 位作 check 位，第二字节作追加在块末尾的 kind 校验字节（见 kind.go）。
+func (idx *Idx) kindSum(data []byte) [sha256.Size]byte {
+	var buf [96]byte
+	return sha256.Sum256(append(append(buf[:0], idx.kindSalt...), data...))
 }
 
 func encodeObject(obj dataObject) ([]byte, error) {
+	return appendObject(nil, obj)
+}
+
+// appendObject 将对象的编码追加到 dst 后返回。
+func appendObject(dst []byte, obj dataObject) ([]byte, error) {
 	if obj.isString {
 		n := len(obj.str)
 		if n < 1 || n > maxStringLen {
 			return nil, fmt.Errorf("string length %d out of range [1, %d]", n, maxStringLen)
 		}
-		out := make([]byte, 1+n)
-		out[0] = 0xC0 | byte(n) // bit7=1, bit6=1, bit5-0=len
-		copy(out[1:], obj.str)
-		return out, nil
+		dst = append(dst, 0xC0|byte(n)) // bit7=1, bit6=1, bit5-0=len
+		return append(dst, obj.str...), nil
 	}
 
 	if err := validateRange(obj.otype, obj.val); err != nil {
 		return nil, err
 	}
 	if head, ok := tryEmbeddedHead(obj.otype, obj.val); ok {
-		return []byte{head}, nil
+		return append(dst, head), nil
 	}
 
 	sw, payload, err := payloadForNumber(obj.otype, obj.val)
@@ -275,10 +396,8 @@ func encodeObject(obj dataObject) ([]byte, error) {
 		return nil, err
 	}
 	head := byte(0x80) | byte(sw<<4) | obj.otype // bit6=0 表示数字
-	out := make([]byte, 1+len(payload))
-	out[0] = head
-	copy(out[1:], payload)
-	return out, nil
+	dst = append(dst, head)
+	return append(dst, payload...), nil
 }
 
 func decodeObject(data []byte) (dataObject, int, error) {
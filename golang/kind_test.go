@@ -0,0 +1,222 @@
+// kind_test.go 覆盖 ForKind 域分隔：同 kind 往返、跨 kind 拒绝率、ErrWrongKind 与声明的 kind 集合。
+package idmix
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+)
+
+func TestForKindRoundTrip(t *testing.T) {
+	m, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	users := m.ForKind("user")
+	if users.Kind() != "user" || m.Kind() != "" {
+		t.Fatalf("kinds: %q %q", users.Kind(), m.Kind())
+	}
+	for v := 0; v < m.Idx().MaxVariants(); v++ {
+		plain, _ := m.EncodeWithVariant(v, uint32(1001), "eu")
+		s, err := users.EncodeWithVariant(v, uint32(1001), "eu")
+		if err != nil {
+			t.Fatal(err)
+		}
+		in, _ := users.Inspect(s)
+		pin, _ := m.Inspect(plain)
+		// 扩展头标记与 ext 字节各 1 字节，kind 校验字节 1 字节
+		if len(in.Binary) != len(pin.Binary)+3 || in.Binary[1] != extMarker || in.Binary[2]&extHasKind == 0 {
+			t.Fatalf("variant %d: kind marker or check byte missing: % x vs % x", v, in.Binary, pin.Binary)
+		}
+		list, err := users.Decode(s)
+		if err != nil || list[0].(uint32) != 1001 || list[1].(string) != "eu" {
+			t.Fatalf("variant %d: %v %v", v, list, err)
+		}
+	}
+	if empty := m.ForKind(""); empty.Kind() != "" {
+		t.Fatal("empty kind should be unbound")
+	} else {
+		a, _ := m.EncodeWithVariant(3, int64(-5))
+		b, _ := empty.EncodeWithVariant(3, int64(-5))
+		if a != b {
+			t.Fatalf("empty kind changed output: %q vs %q", a, b)
+		}
+	}
+}
+
+func TestForKindWrongKindError(t *testing.T) {
+	m, err := New(WithKinds("user", "order"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	users, orders := m.ForKind("user"), m.ForKind("order")
+	s, err := users.EncodeWithVariant(0, uint32(42))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = orders.Decode(s)
+	var km *KindMismatchError
+	if !errors.Is(err, ErrWrongKind) || !errors.As(err, &km) || km.Want != "order" || km.Got != "user" {
+		t.Fatalf("got %v", err)
+	}
+	t.Logf("order.Decode(user token) => %v", err)
+
+	// 未区分 kind 的串交给 kind 实例，同样识别
+	plain, _ := m.EncodeWithVariant(0, uint32(42))
+	if _, err := users.Decode(plain); !errors.Is(err, ErrWrongKind) {
+		t.Fatalf("plain token: %v", err)
+	}
+	// 未声明 kind 的实例只能报告普通解码错误
+	other, _ := New()
+	if _, err := other.ForKind("order").Decode(s); err == nil || errors.Is(err, ErrWrongKind) {
+		t.Fatalf("undeclared kinds: %v", err)
+	}
+	if _, err := users.Inspect(plain); !errors.Is(err, ErrWrongKind) {
+		t.Fatalf("Inspect: %v", err)
+	}
+	// ForKind 不修改声明的集合：未声明的 team 不会被识别，也不影响兄弟实例
+	team, _ := m.ForKind("team").EncodeWithVariant(0, uint32(42))
+	if _, err := orders.Decode(team); err == nil || errors.Is(err, ErrWrongKind) {
+		t.Fatalf("undeclared team token: %v", err)
+	}
+	if _, err := New(WithKinds("user", "")); err == nil {
+		t.Fatal("expected empty kind error")
+	}
+}
+
+// TestForKindCorruptTokens 随机损坏的串不应被误报为 ErrWrongKind。
+// TestForKindUnboundDecoder 未绑定 kind 的解码器与绑定 kind 的解码器互相拒绝对方的编码串（按 ext 的 kind 标记，不依赖校验）。
+func TestForKindUnboundDecoder(t *testing.T) {
+	for _, opts := range [][]IdxOption{nil, {WithChecksum(ChecksumCRC8)}} {
+		idx, err := NewIdx(opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		base, _ := New(WithIdx(idx))
+		users := base.ForKind("user")
+		r := rand.New(rand.NewSource(1))
+		const n = 30000
+		for i := 0; i < n; i++ {
+			v := []any{uint8(r.Intn(256))}
+			if i%2 == 1 {
+				v = []any{r.Uint32(), "eu"}
+			}
+			s, _ := users.Encode(v...)
+			if list, err := base.Decode(s); err == nil {
+				t.Fatalf("%s: unbound decoder accepted %q as %v", idx.ChecksumMode(), s, list)
+			}
+			p, _ := base.Encode(v...)
+			if list, err := users.Decode(p); err == nil {
+				t.Fatalf("%s: kind decoder accepted unbound %q as %v", idx.ChecksumMode(), p, list)
+			}
+		}
+		t.Logf("%s: %d kind/unbound tokens rejected both ways", idx.ChecksumMode(), n)
+	}
+}
+
+func TestForKindCorruptTokens(t *testing.T) {
+	kinds := []string{"user", "order", "invoice", "team", "file", "session"}
+	m, _ := New(WithKinds(kinds...))
+	users := m.ForKind("user")
+	r := rand.New(rand.NewSource(1))
+	const n = 20000
+	wrongKind := 0
+	for i := 0; i < n; i++ {
+		s, _ := users.Encode(r.Uint32())
+		b := []byte(s)
+		b[r.Intn(len(b))] = DefaultAlphabet[r.Intn(len(DefaultAlphabet))]
+		if _, err := users.Decode(string(b)); errors.Is(err, ErrWrongKind) {
+			wrongKind++
+		}
+	}
+	t.Logf("%d/%d single-character corruptions reported as ErrWrongKind", wrongKind, n)
+	if rate := float64(wrongKind) / n; rate > 0.01 {
+		t.Fatalf("corrupt tokens misreported as ErrWrongKind at %.4f", rate)
+	}
+}
+
+// TestForKindRejectionRate 统计跨 kind 解码被拒绝的比例。
+//
+// kind 校验字节使任意载荷的误接受率不高于 2^-(8+checkBits)，单字节内嵌对象同样适用。
+func TestForKindRejectionRate(t *testing.T) {
+	const perPair = 2000
+	kinds := []string{"user", "order", "invoice", "team", "file", "session"}
+	cases := []struct {
+		name    string
+		opts    []IdxOption
+		payload func(r *rand.Rand) []any
+		min     float64
+	}{
+		{"checkBits2_uint32", nil, func(r *rand.Rand) []any { return []any{r.Uint32()} }, 0.995},
+		{"checkBits2_uint64_string", nil, func(r *rand.Rand) []any { return []any{r.Uint64(), "eu"} }, 0.995},
+		{"checkBits2_small", nil, func(r *rand.Rand) []any { return []any{uint8(r.Intn(32))} }, 0.995},
+		{"checkBits1_small", []IdxOption{WithCheckBits(1)}, func(r *rand.Rand) []any { return []any{uint8(r.Intn(32))} }, 0.99},
+		{"crc8_small", []IdxOption{WithChecksum(ChecksumCRC8)}, func(r *rand.Rand) []any { return []any{uint8(r.Intn(32))} }, 0.999},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			idx, err := NewIdx(c.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			m, err := New(WithIdx(idx), WithKinds(kinds...))
+			if err != nil {
+				t.Fatal(err)
+			}
+			r := rand.New(rand.NewSource(1))
+			total, rejected := 0, 0
+			worst, worstPair := 1.0, ""
+			for _, from := range kinds {
+				for _, to := range kinds {
+					if from == to {
+						continue
+					}
+					enc, dec := m.ForKind(from), m.ForKind(to)
+					n := 0
+					for i := 0; i < perPair; i++ {
+						s, err := enc.Encode(c.payload(r)...)
+						if err != nil {
+							t.Fatal(err)
+						}
+						if _, err := dec.Decode(s); err != nil {
+							if !errors.Is(err, ErrWrongKind) {
+								t.Fatalf("%q: expected ErrWrongKind, got %v", s, err)
+							}
+							n++
+						}
+					}
+					if rate := float64(n) / perPair; rate < worst {
+						worst, worstPair = rate, from+"→"+to
+					}
+					total += perPair
+					rejected += n
+				}
+			}
+			rate := float64(rejected) / float64(total)
+			t.Logf("%s: rejected %d/%d (%.2f%%), worst pair %s %.2f%%", c.name, rejected, total, rate*100, worstPair, worst*100)
+			if worst < c.min {
+				t.Fatalf("worst pair %s rejection rate %.4f < %.2f", worstPair, worst, c.min)
+			}
+		})
+	}
+}
+
+func BenchmarkForKind(b *testing.B) {
+	m, _ := New()
+	for _, c := range []struct {
+		name string
+		m    *IdMix
+	}{{"plain", m}, {"kind", m.ForKind("user")}} {
+		s, _ := c.m.EncodeWithVariant(0, uint32(1001), "eu")
+		b.Run(c.name+"/encode", func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				_, _ = c.m.EncodeWithVariant(0, uint32(1001), "eu")
+			}
+		})
+		b.Run(c.name+"/decode", func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				_, _ = c.m.Decode(s)
+			}
+		})
+	}
+}
@@ -0,0 +1,276 @@
+// limits.go 实现解码资源上限（DecodeLimits）：在进制转换与对象解析等昂贵步骤之前拒绝超限输入，
+// 使公开的解码接口无法被超长串拖入平方级的大整数运算。
+//
+// 检查顺序（任一失败即返回，均不做大整数运算）：
+//
+//  1. 字符数：len(s) ≤ MaxChars 时 O(1) 通过；len(s) > 4·MaxChars 时 O(1) 拒绝；其余 O(MaxChars) 计数。
+//  2. RadixCodec 由有效字符数估算解码后长度的下界，超过 MaxBinaryLen 时拒绝（最多扫描 O(MaxBinaryLen) 个有效字符）。
+//  3. 二进制块长度、header 中的对象个数（O(1)），以及逐对象的类型与字符串总字节数。
+package idmix
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"unicode/utf8"
+)
+
+// OTypeSet 为对象类型集合，用于 DecodeLimits.AllowedOTypes。
+type OTypeSet uint16
+
+const (
+	OTypeUint8 OTypeSet = 1 << iota
+	OTypeUint16
+	OTypeUint32
+	OTypeUint64
+	OTypeInt8
+	OTypeInt16
+	OTypeInt32
+	OTypeInt64
+	OTypeString
+
+	// OTypeIntegers 为全部整数类型。
+	OTypeIntegers = OTypeUint8 | OTypeUint16 | OTypeUint32 | OTypeUint64 |
+		OTypeInt8 | OTypeInt16 | OTypeInt32 | OTypeInt64
+	// OTypeAll 为全部类型（整数与字符串）。
+	OTypeAll = OTypeIntegers | OTypeString
+)
+
+var otypeSetNames = []string{"uint8", "uint16", "uint32", "uint64", "int8", "int16", "int32", "int64", "string"}
+
+func (s OTypeSet) String() string {
+	var names []string
+	for i, name := range otypeSetNames {
+		if s&(1<<i) != 0 {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return "none"
+	}
+	return strings.Join(names, "|")
+}
+
+// otypeOf 返回对象对应的 OTypeSet 位。
+func otypeOf(obj dataObject) OTypeSet {
+	if obj.isString {
+		return OTypeString
+	}
+	return 1 << obj.otype
+}
+
+// DecodeLimits 为解码资源上限；字段为 0 表示不限制。
+type DecodeLimits struct {
+	// MaxChars 为输入文本的最大字符数（按 rune 计）。
+	MaxChars int
+	// MaxBinaryLen 为文本层还原后二进制块的最大字节数。
+	MaxBinaryLen int
+	// MaxObjects 为单个块允许的最大对象个数。
+	MaxObjects int
+	// MaxStringBytes 为块内全部字符串对象的字节数之和上限。
+	MaxStringBytes int
+	// AllowedOTypes 为允许出现的对象类型，0 为不限。
+	AllowedOTypes OTypeSet
+}
+
+func (l DecodeLimits) validate() error {
+	if l.MaxChars < 0 || l.MaxBinaryLen < 0 || l.MaxObjects < 0 || l.MaxStringBytes < 0 {
+		return errors.New("decode limits cannot be negative")
+	}
+	if l.AllowedOTypes&^OTypeAll != 0 {
+		return fmt.Errorf("unknown otype bits %#x", uint16(l.AllowedOTypes&^OTypeAll))
+	}
+	return nil
+}
+
+var (
+	// ErrLimitExceeded 输入超过 DecodeLimits 的某项上限（可用 errors.Is 判断）。
+	ErrLimitExceeded = errors.New("decode limit exceeded")
+	// ErrOTypeNotAllowed 块中出现 DecodeLimits.AllowedOTypes 之外的对象类型。
+	ErrOTypeNotAllowed = errors.New("object type not allowed")
+)
+
+// LimitError 描述超出的上限；AtLeast 为 true 时 Got 只是下界（未完整计数即已拒绝）。
+type LimitError struct {
+	Limit   string
+	Got     int
+	Max     int
+	AtLeast bool
+}
+
+func (e *LimitError) Error() string {
+	qualifier := ""
+	if e.AtLeast {
+		qualifier = "at least "
+	}
+	return fmt.Sprintf("%v: %s is %s%d, max %d", ErrLimitExceeded, e.Limit, qualifier, e.Got, e.Max)
+}
+
+func (e *LimitError) Is(target error) bool { return target == ErrLimitExceeded }
+
+// checkText 检查输入字符数；l 为 nil 时不检查。
+func (l *DecodeLimits) checkText(s string) error {
+	if l == nil || l.MaxChars == 0 || len(s) <= l.MaxChars {
+		return nil
+	}
+	if len(s) > utf8.UTFMax*l.MaxChars {
+		return &LimitError{Limit: "maxChars", Got: (len(s) + utf8.UTFMax - 1) / utf8.UTFMax, Max: l.MaxChars, AtLeast: true}
+	}
+	if n := utf8.RuneCountInString(s); n > l.MaxChars {
+		return &LimitError{Limit: "maxChars", Got: n, Max: l.MaxChars}
+	}
+	return nil
+}
+
+func (l *DecodeLimits) checkBinary(n int, atLeast bool) error {
+	if l == nil || l.MaxBinaryLen == 0 || n <= l.MaxBinaryLen {
+		return nil
+	}
+	return &LimitError{Limit: "maxBinaryLen", Got: n, Max: l.MaxBinaryLen, AtLeast: atLeast}
+}
+
+func (l *DecodeLimits) checkCount(count int) error {
+	if l == nil || l.MaxObjects == 0 || count <= l.MaxObjects {
+		return nil
+	}
+	return &LimitError{Limit: "maxObjects", Got: count, Max: l.MaxObjects}
+}
+
+// checkObject 检查对象类型，并把字符串字节数累加到 *strBytes 后与上限比较。
+func (l *DecodeLimits) checkObject(obj dataObject, strBytes *int) error {
+	if l == nil {
+		return nil
+	}
+	if t := otypeOf(obj); l.AllowedOTypes != 0 && l.AllowedOTypes&t == 0 {
+		return fmt.Errorf("%w: %s (allowed: %s)", ErrOTypeNotAllowed, t, l.AllowedOTypes)
+	}
+	if obj.isString {
+		*strBytes += len(obj.str)
+		if l.MaxStringBytes != 0 && *strBytes > l.MaxStringBytes {
+			return &LimitError{Limit: "maxStringBytes", Got: *strBytes, Max: l.MaxStringBytes, AtLeast: true}
+		}
+	}
+	return nil
+}
+
+// WithDecodeLimits 设置解码资源上限，作用于 Decode、Inspect、DecodeTagged 等全部解码入口。
+//
+// Codec 为 RadixCodec 时，字符数与估算的二进制长度在大整数运算之前检查；
+// 其他 Codec 先检查字符数，解码后再检查二进制长度。Idx 侧的对象个数、类型与字符串字节数
+// 由 Idx.WithLimits 派生的副本检查（New 在应用全部 Option 后自动派生）。
+func WithDecodeLimits(limits DecodeLimits) Option {
+	return func(m *IdMix) error {
+		if err := limits.validate(); err != nil {
+			return err
+		}
+		m.limits = &limits
+		return nil
+	}
+}
+
+// Limits 返回实例的解码上限；未设置时 ok 为 false。
+func (m *IdMix) Limits() (limits DecodeLimits, ok bool) {
+	if m.limits == nil {
+		return DecodeLimits{}, false
+	}
+	return *m.limits, true
+}
+
+// decodeText 在检查上限后调用 Codec.Decode，严格模式下再检查文本是否为规范写法（解码入口共用）。
+func (m *IdMix) decodeText(sc *batchScratch, s string) ([]byte, error) {
+	return m.checkedText(s, func(s string) ([]byte, error) {
+		if rc, ok := m.codec.(*RadixCodec); ok && sc != nil {
+			return rc.decodeWith(&sc.radix, s)
+		}
+		return m.codec.Decode(s)
+	})
+}
+
+// checkedText 为 decodeText 的检查流程，文本层由 decode 完成（DecodeCorrected 以纠错解码代替 Codec.Decode）。
+func (m *IdMix) checkedText(s string, decode func(string) ([]byte, error)) ([]byte, error) {
+	if rc, ok := m.codec.(*RadixCodec); ok {
+		if err := rc.precheck(s, m.limits); err != nil {
+			return nil, err
+		}
+	} else if err := m.limits.checkText(s); err != nil {
+		return nil, err
+	}
+	data, err := decode(s)
+	if err != nil {
+		return nil, m.filterTypos(err)
+	}
+	if m.strict {
+		if err := m.checkCanonicalText(s, data); err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
+// WithLimits 返回在解码时检查 limits 的 Idx 副本（二进制长度、对象个数、类型与字符串字节数）。
+func (idx *Idx) WithLimits(limits DecodeLimits) (*Idx, error) {
+	if err := limits.validate(); err != nil {
+		return nil, err
+	}
+	c := *idx
+	c.limits = &limits
+	return &c, nil
+}
+
+// WithLimits 返回在解码时检查 limits 的 RadixCodec 副本（字符数与二进制长度）。
+func (rc *RadixCodec) WithLimits(limits DecodeLimits) (*RadixCodec, error) {
+	if err := limits.validate(); err != nil {
+		return nil, err
+	}
+	c := *rc
+	c.limits = &limits
+	return &c, nil
+}
+
+// precheck 在进制转换之前检查字符数与解码后长度的下界。
+func (rc *RadixCodec) precheck(s string, l *DecodeLimits) error {
+	if l == nil {
+		return nil
+	}
+	if err := l.checkText(s); err != nil {
+		return err
+	}
+	if l.MaxBinaryLen == 0 {
+		return nil
+	}
+	return l.checkBinary(rc.minDecodedLen(s, l.MaxBinaryLen), true)
+}
+
+// minDecodedLen 由字符数估算 Decode 结果长度的下界（不做大整数运算）。
+// 下界一旦超过 stopAt 即停止计数，因此除前导零字符外只扫描 O(stopAt) 个字符。
+func (rc *RadixCodec) minDecodedLen(s string, stopAt int) int {
+	bitsPerChar := math.Log2(float64(rc.base))
+	estimate := func(digits int) int {
+		switch rc.mode {
+		case radixChunked:
+			return digits / rc.chunkDigits[chunkBytes] * chunkBytes
+		case radixCompact:
+			// 双射 N 进制 k 位 ≥ N^(k-1)；双射 256 进制 m 字节 < 256^(m+1)
+			return int(float64(digits-1)*bitsPerChar/8) - 1
+		}
+		// n ≥ base^(digits-1)，二进制至少 bits/8 字节，减去 2 字节长度前缀
+		return int(float64(digits-1)*bitsPerChar/8) - 2
+	}
+	// 超过 enough 个有效字符时下界必然大于 stopAt
+	enough := int(float64(stopAt+3)*8/bitsPerChar) + 2
+	if rc.mode == radixChunked {
+		enough = (stopAt/chunkBytes + 1) * rc.chunkDigits[chunkBytes]
+	}
+	digits := 0
+	zero := rc.chars[0]
+	for _, r := range s {
+		if digits == 0 && r == zero && rc.mode == radixStandard {
+			continue // 标准模式的前导零字符不增加数值
+		}
+		if digits++; digits > enough {
+			break
+		}
+	}
+	return estimate(digits)
+}
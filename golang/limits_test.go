@@ -0,0 +1,205 @@
+// limits_test.go 覆盖 DecodeLimits 各项上限、不误拒合法输入，以及超限拒绝的开销（O(1)/O(n)）。
+package idmix
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// TestDecodeLimitsText 超长输入在进制转换前被拒绝，且拒绝不随输入长度分配内存。
+func TestDecodeLimitsText(t *testing.T) {
+	m, err := New(WithDecodeLimits(DecodeLimits{MaxChars: 32}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ok, _ := m.Encode(uint32(1001), "eu")
+	if _, err := m.Decode(ok); err != nil {
+		t.Fatal(err)
+	}
+
+	huge := strings.Repeat("Z", 1<<20)
+	for _, s := range []string{huge, strings.Repeat("Z", 33), strings.Repeat("订", 33)} {
+		_, err := m.Decode(s)
+		var le *LimitError
+		if !errors.As(err, &le) || le.Limit != "maxChars" || !errors.Is(err, ErrLimitExceeded) {
+			t.Fatalf("%d bytes: %v", len(s), err)
+		}
+		t.Logf("%d bytes => %v", len(s), err)
+	}
+	allocs := testing.AllocsPerRun(20, func() { _, _ = m.Decode(huge) })
+	if allocs > 1 {
+		t.Fatalf("rejecting 1 MiB allocates %.0f times", allocs)
+	}
+	for _, decode := range []func(string) error{
+		func(s string) error { _, err := m.Inspect(s); return err },
+		func(s string) error { _, _, err := m.DecodeTagged(s); return err },
+		func(s string) error { _, err := m.DecodeValidNow(s); return err },
+	} {
+		if err := decode(huge); !errors.Is(err, ErrLimitExceeded) {
+			t.Fatalf("decode entry ignores limits: %v", err)
+		}
+	}
+	if l, ok := m.Limits(); !ok || l.MaxChars != 32 {
+		t.Fatalf("Limits() = %+v %v", l, ok)
+	}
+}
+
+// TestRadixMinDecodedLen 由字符数估算的长度下界从不超过实际解码长度（不误拒），且足以拒绝超长串。
+func TestRadixMinDecodedLen(t *testing.T) {
+	r := rand.New(rand.NewSource(43))
+	for _, build := range []func(string, ...AlphabetCheck) (*RadixCodec, error){
+		NewRadixCodec, NewCompactRadixCodec, NewChunkedRadixCodec,
+	} {
+		for _, alphabet := range []string{"01", AlphabetCrockford32, DefaultAlphabet, "一二三四五六七八九十"} {
+			rc, err := build(alphabet)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for n := 0; n <= 80; n++ {
+				data := make([]byte, n)
+				r.Read(data)
+				if n > 0 && r.Intn(3) == 0 {
+					data[0] = 0 // 覆盖前导零
+				}
+				s, _ := rc.Encode(data)
+				if n == 0 && rc.mode == radixStandard {
+					continue // 标准模式的空载荷编码为单个零字符，本身不可解码
+				}
+				if est := rc.minDecodedLen(s, n); est > n {
+					t.Fatalf("base %d mode %d n=%d: estimate %d", rc.base, rc.mode, n, est)
+				}
+				limited, _ := rc.WithLimits(DecodeLimits{MaxBinaryLen: n})
+				if back, err := limited.Decode(s); err != nil || len(back) != n {
+					t.Fatalf("base %d mode %d n=%d: %v", rc.base, rc.mode, n, err)
+				}
+				if n > 1 { // MaxBinaryLen 为 0 表示不限
+					tight, _ := rc.WithLimits(DecodeLimits{MaxBinaryLen: n - 1})
+					if _, err := tight.Decode(s); !errors.Is(err, ErrLimitExceeded) {
+						t.Fatalf("base %d mode %d n=%d: tight limit %v", rc.base, rc.mode, n, err)
+					}
+				}
+			}
+		}
+	}
+
+	// 前导零字符不增加数值，不应被估算为长载荷
+	rc, _ := NewRadixCodec(DefaultAlphabet)
+	s, _ := rc.Encode([]byte{7})
+	limited, _ := rc.WithLimits(DecodeLimits{MaxBinaryLen: 4})
+	if back, err := limited.Decode(strings.Repeat("a", 500) + s); err != nil || len(back) != 1 {
+		t.Fatalf("leading zeros: %x %v", back, err)
+	}
+	// 10 KB 的随机串在大整数运算之前被拒绝
+	long := strings.Repeat("Zz9", 10<<10/3)
+	_, err := limited.Decode(long)
+	var le *LimitError
+	if !errors.As(err, &le) || le.Limit != "maxBinaryLen" || !le.AtLeast {
+		t.Fatalf("long: %v", err)
+	}
+	t.Logf("%d chars => %v", len(long), err)
+	shuffled, _ := New(WithCodec(limited), WithSecretAlphabet([]byte("k")))
+	if shuffled.Codec().(*RadixCodec).limits == nil {
+		t.Fatal("WithSecretAlphabet should keep codec limits")
+	}
+}
+
+// TestDecodeLimitsIdx 对象个数、字符串总字节数与允许类型在 IDX 层检查。
+func TestDecodeLimitsIdx(t *testing.T) {
+	plain, _ := New()
+	cases := []struct {
+		name   string
+		limits DecodeLimits
+		values []any
+		target error
+	}{
+		{"max_objects", DecodeLimits{MaxObjects: 4}, []any{1, 2, 3, 4, 5}, ErrLimitExceeded},
+		{"max_string_bytes", DecodeLimits{MaxStringBytes: 15}, []any{"0123456789", "0123456789"}, ErrLimitExceeded},
+		{"integers_only", DecodeLimits{AllowedOTypes: OTypeIntegers}, []any{uint8(1), "eu"}, ErrOTypeNotAllowed},
+		{"unsigned_only", DecodeLimits{AllowedOTypes: OTypeUint8 | OTypeUint32}, []any{uint32(1), int8(-1)}, ErrOTypeNotAllowed},
+		{"max_binary_len", DecodeLimits{MaxBinaryLen: 8}, []any{uint64(1 << 40), uint64(1 << 40)}, ErrLimitExceeded},
+	}
+	for _, c := range cases {
+		for _, codec := range []Codec{defaultCodecInstance(), NewBase64URLCodec()} {
+			m, err := New(WithCodec(codec), WithDecodeLimits(c.limits))
+			if err != nil {
+				t.Fatal(err)
+			}
+			s, err := plain.Encode(c.values...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if codec != defaultCodecInstance() {
+				data, _ := plain.Idx().Encode(c.values...)
+				s, _ = codec.Encode(data)
+			}
+			if _, err := m.Decode(s); !errors.Is(err, c.target) {
+				t.Fatalf("%s %T: %v", c.name, codec, err)
+			} else {
+				t.Logf("%s %T => %v", c.name, codec, err)
+			}
+			// 上限内的输入照常解码
+			if _, err := m.Decode(mustEncode(t, m, uint8(1))); err != nil {
+				t.Fatalf("%s: within limits: %v", c.name, err)
+			}
+		}
+	}
+
+	idx, _ := NewIdx()
+	limited, err := idx.WithLimits(DecodeLimits{MaxObjects: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := idx.Encode(1, 2)
+	if _, err := limited.Decode(data); !errors.Is(err, ErrLimitExceeded) {
+		t.Fatalf("Idx.WithLimits: %v", err)
+	}
+	for _, bad := range []DecodeLimits{{MaxChars: -1}, {MaxObjects: -1}, {AllowedOTypes: 1 << 12}} {
+		if _, err := New(WithDecodeLimits(bad)); err == nil {
+			t.Fatalf("%+v: expected error", bad)
+		}
+	}
+	if got := (OTypeUint8 | OTypeString).String(); got != "uint8|string" {
+		t.Fatalf("OTypeSet.String() = %q", got)
+	}
+}
+
+func mustEncode(t *testing.T, m *IdMix, values ...any) string {
+	t.Helper()
+	s, err := m.Encode(values...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+// BenchmarkDecodeLimits 对比有无上限时解码超长输入的开销：
+// maxChars 拒绝为 O(1)，maxBinaryLen 估算为 O(n)，无上限时为大整数运算 O(n²)。
+func BenchmarkDecodeLimits(b *testing.B) {
+	unlimited, _ := New()
+	byChars, _ := New(WithDecodeLimits(DecodeLimits{MaxChars: 64}))
+	byBinary, _ := New(WithDecodeLimits(DecodeLimits{MaxBinaryLen: 64}))
+	for _, size := range []int{1 << 10, 64 << 10, 1 << 20} {
+		s := strings.Repeat("Zz9", size/3)
+		b.Run(fmt.Sprintf("maxChars/%dKB", size>>10), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				_, _ = byChars.Decode(s)
+			}
+		})
+		b.Run(fmt.Sprintf("maxBinaryLen/%dKB", size>>10), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				_, _ = byBinary.Decode(s)
+			}
+		})
+		if size > 64<<10 {
+			continue // 无上限时 1 MB 需数十秒
+		}
+		b.Run(fmt.Sprintf("unlimited/%dKB", size>>10), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				_, _ = unlimited.Decode(s)
+			}
+		})
+	}
+}
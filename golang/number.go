@@ -5,19 +5,25 @@
 package idmix
 
 import (
+	"errors"
 	"fmt"
+	"strconv"
 )
 
 func normalizeObjects(values []any) ([]dataObject, error) {
-	out := make([]dataObject, len(values))
+	return appendObjects(make([]dataObject, 0, len(values)), values)
+}
+
+// appendObjects 将 values 规范化后追加到 dst 后返回（批量编码复用 dst，见 batch.go）。
+func appendObjects(dst []dataObject, values []any) ([]dataObject, error) {
 	for i, v := range values {
 		obj, err := objectFromAny(v)
 		if err != nil {
 			return nil, fmt.Errorf("value[%d]: %w", i, err)
 		}
-		out[i] = obj
+		dst = append(dst, obj)
 	}
-	return out, nil
+	return dst, nil
 }
 
 func objectFromAny(v any) (dataObject, error) {
@@ -68,3 +74,61 @@ func objectFromAny(v any) (dataObject, error) {
 func objectFromAnyValue(v any) (dataObject, error) {
 	return objectFromAny(v)
 }
+
+// TypedValue 是值的语言无关 JSON 表示，与 testdata/cross_language_vectors.json 的
+// {otype, val} 结构一致：整数以十进制字符串存放（避免 JSON 数字丢失 64 位精度），
+// 字符串值放在 Str 字段。整数必须显式给出 OType（0 即 uint8，缺省不视为 0），
+// 字符串不得同时带 OType 或 Val。
+type TypedValue struct {
+	OType *int   `json:"otype,omitempty"`
+	Val   string `json:"val,omitempty"`
+	Str   string `json:"str,omitempty"`
+}
+
+// NewTypedValue 将 Encode 支持的值转为 TypedValue（[]byte 按字符串处理）。
+func NewTypedValue(v any) (TypedValue, error) {
+	obj, err := objectFromAny(v)
+	if err != nil {
+		return TypedValue{}, err
+	}
+	if obj.isString {
+		return TypedValue{Str: string(obj.str)}, nil
+	}
+	otype := int(obj.otype)
+	return TypedValue{OType: &otype, Val: formatCrossLangVal(obj.otype, obj.val)}, nil
+}
+
+// Value 还原为 Encode 可接受的 Go 具体类型（uint16、int64、string 等）。
+func (tv TypedValue) Value() (any, error) {
+	if tv.Str != "" {
+		if tv.OType != nil || tv.Val != "" {
+			return nil, errors.New("str cannot be combined with otype or val")
+		}
+		return tv.Str, nil
+	}
+	if tv.OType == nil {
+		return nil, errors.New("otype is required for integer values")
+	}
+	if *tv.OType < otypeUint8 || *tv.OType > otypeInt64 {
+		return nil, fmt.Errorf("invalid otype %d", *tv.OType)
+	}
+	otype := uint8(*tv.OType)
+	var val int64
+	if isUnsigned(otype) {
+		u, err := strconv.ParseUint(tv.Val, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("otype %d: %w", otype, err)
+		}
+		val = int64(u)
+	} else {
+		i, err := strconv.ParseInt(tv.Val, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("otype %d: %w", otype, err)
+		}
+		val = i
+	}
+	if err := validateRange(otype, val); err != nil {
+		return nil, err
+	}
+	return materializeValue(dataObject{otype: otype, val: val})
+}
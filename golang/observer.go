@@ -0,0 +1,287 @@
+// observer.go 提供编解码结果的观测钩子（Observer）及 expvar、log/slog 适配器。
+//
+// 每次 Encode / Decode 类调用结束后上报一个 Event：操作、耗时、文本与二进制长度、variant 和错误类别。
+// 错误类别用于区分「有人在猜编码串」（malformed、checksum 激增）与「客户端配置不一致」
+// （format、wrongKind、version 激增）。未设置 Observer 时只多一次 nil 判断，不计时、不分配。
+package idmix
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// Operation 为被观测的操作。
+type Operation uint8
+
+const (
+	// OpEncode 为 Encode、EncodeWithVariant、EncodeTagged（及基于它们的 EncodeWithTTL、EncodeBatch）。
+	OpEncode Operation = iota + 1
+	// OpDecode 为 Decode（及基于它的 DecodeBatch）。
+	OpDecode
+	// OpInspect 为 Inspect。
+	OpInspect
+	// OpDecodeTagged 为 DecodeTagged。
+	OpDecodeTagged
+	// OpDecodeValid 为 DecodeValid、DecodeValidNow，错误含有效期校验的结果。
+	OpDecodeValid
+	// OpDecodeCorrected 为 DecodeCorrected。
+	OpDecodeCorrected
+	// OpCanonical 为 Canonical、Equal、Fingerprint 的解码（Equal 每个参数各上报一次）。
+	OpCanonical
+	// OpTranscode 为 Transcode 的解码，由 from 上报；重新编码由 to 以 OpEncode 上报。
+	OpTranscode
+)
+
+var operationNames = [...]string{
+	OpEncode:          "encode",
+	OpDecode:          "decode",
+	OpInspect:         "inspect",
+	OpDecodeTagged:    "decodeTagged",
+	OpDecodeValid:     "decodeValid",
+	OpDecodeCorrected: "decodeCorrected",
+	OpCanonical:       "canonical",
+	OpTranscode:       "transcode",
+}
+
+func (op Operation) String() string {
+	if int(op) < len(operationNames) && operationNames[op] != "" {
+		return operationNames[op]
+	}
+	return fmt.Sprintf("Operation(%d)", uint8(op))
+}
+
+// ErrorClass 为错误的粗粒度分类。
+type ErrorClass uint8
+
+const (
+	// ErrClassNone 表示成功。
+	ErrClassNone ErrorClass = iota
+	// ErrClassMalformed 文本层无法解码（非法字符、长度不符等），常见于随意构造的输入。
+	ErrClassMalformed
+	// ErrClassChecksum 文本可解码但校验失败（ErrChecksumMismatch、ErrCheckCharacter 等），常见于猜测或篡改。
+	ErrClassChecksum
+	// ErrClassFormat 通过校验但 IDX 结构无效（对象个数、对象头等），常见于配置不一致。
+	ErrClassFormat
+	// ErrClassWrongKind 编码串属于另一个 kind（ErrWrongKind）。
+	ErrClassWrongKind
+	// ErrClassVersion 不支持的版本标记（ErrUnsupportedVersion）。
+	ErrClassVersion
+	// ErrClassLimit 超出 DecodeLimits（ErrLimitExceeded、ErrOTypeNotAllowed）。
+	ErrClassLimit
+	// ErrClassNonCanonical 严格模式拒绝的非规范形式（ErrNonCanonical）。
+	ErrClassNonCanonical
+	// ErrClassInvalidInput 编码时的输入无效（值类型、范围、个数等）。
+	ErrClassInvalidInput
+	// ErrClassExpired 编码串已过期（ErrExpired）。
+	ErrClassExpired
+	// ErrClassNotYetValid 签发时间晚于当前时间（ErrNotYetValid），激增时多为时钟不同步。
+	ErrClassNotYetValid
+	// ErrClassNoTTL 编码串不带有效期（ErrNoTTL），常见于把普通编码串交给 DecodeValid。
+	ErrClassNoTTL
+)
+
+var errorClassNames = [...]string{
+	ErrClassNone:         "ok",
+	ErrClassMalformed:    "malformed",
+	ErrClassChecksum:     "checksum",
+	ErrClassFormat:       "format",
+	ErrClassWrongKind:    "wrongKind",
+	ErrClassVersion:      "version",
+	ErrClassLimit:        "limit",
+	ErrClassNonCanonical: "nonCanonical",
+	ErrClassInvalidInput: "invalidInput",
+	ErrClassExpired:      "expired",
+	ErrClassNotYetValid:  "notYetValid",
+	ErrClassNoTTL:        "noTTL",
+}
+
+func (c ErrorClass) String() string {
+	if int(c) < len(errorClassNames) {
+		return errorClassNames[c]
+	}
+	return fmt.Sprintf("ErrorClass(%d)", uint8(c))
+}
+
+// Event 为一次被观测调用的结果。Variant 在文本层失败或无法得知时为 -1（包括选定 variant 前即被拒绝的编码）。
+type Event struct {
+	Op        Operation
+	Duration  time.Duration
+	TextLen   int // 编码串字节数
+	BinaryLen int // 二进制块字节数，文本层失败时为 0
+	Variant   int
+	Class     ErrorClass
+	Err       error
+}
+
+// Observer 接收编解码事件；可能被多个 goroutine 并发调用，实现须并发安全且尽快返回。
+type Observer interface {
+	Observe(e Event)
+}
+
+// ObserverFunc 将函数适配为 Observer。
+type ObserverFunc func(e Event)
+
+func (f ObserverFunc) Observe(e Event) { f(e) }
+
+// WithObserver 设置观测钩子；ForKind 派生的实例共享同一个 Observer。
+func WithObserver(o Observer) Option {
+	return func(m *IdMix) error {
+		if o == nil {
+			return errors.New("observer cannot be nil")
+		}
+		m.observer = o
+		return nil
+	}
+}
+
+// classifyDecode 对解码错误分类；textFailed 表示错误发生在文本层（Codec.Decode 及其前置检查）。
+func classifyDecode(err error, textFailed bool) ErrorClass {
+	switch {
+	case err == nil:
+		return ErrClassNone
+	case errors.Is(err, ErrLimitExceeded), errors.Is(err, ErrOTypeNotAllowed):
+		return ErrClassLimit
+	case errors.Is(err, ErrNonCanonical):
+		return ErrClassNonCanonical
+	case errors.Is(err, ErrWrongKind):
+		return ErrClassWrongKind
+	case errors.Is(err, ErrUnsupportedVersion):
+		return ErrClassVersion
+	case errors.Is(err, ErrExpired):
+		return ErrClassExpired
+	case errors.Is(err, ErrNotYetValid):
+		return ErrClassNotYetValid
+	case errors.Is(err, ErrNoTTL):
+		return ErrClassNoTTL
+	case errors.Is(err, ErrChecksumMismatch), errors.Is(err, ErrCheckCharacter), errors.Is(err, ErrTransformChecksum):
+		return ErrClassChecksum
+	case textFailed:
+		return ErrClassMalformed
+	}
+	return ErrClassFormat
+}
+
+// observeDecode 上报一次解码；data 为文本层还原的二进制块（文本层失败时为 nil）。
+func (m *IdMix) observeDecode(op Operation, start time.Time, s string, data []byte, err error, textFailed bool) {
+	e := Event{
+		Op:        op,
+		Duration:  time.Since(start),
+		TextLen:   len(s),
+		BinaryLen: len(data),
+		Variant:   -1,
+		Class:     classifyDecode(err, textFailed),
+		Err:       err,
+	}
+	if len(data) > 0 {
+		e.Variant, _ = m.idx.VariantOf(data)
+	}
+	m.observer.Observe(e)
+}
+
+// encodeRejected 上报在选定 variant 之前即被拒绝的编码（值个数、tag、有效期等），Variant 为 -1。
+func (m *IdMix) encodeRejected(err error) (string, error) {
+	if m.observer != nil {
+		m.observer.Observe(Event{Op: OpEncode, Variant: -1, Class: ErrClassInvalidInput, Err: err})
+	}
+	return "", err
+}
+
+// decodeObserved 为各解码入口共用的两层解码，设置了 Observer 时上报结果。
+// sc 非 nil 时文本层复用其缓冲区，返回的二进制块在下次使用 sc 前有效（见 batch.go）。
+func (m *IdMix) decodeObserved(sc *batchScratch, op Operation, s string) ([]byte, []any, error) {
+	return m.decodeChecked(op, s, func(s string) ([]byte, error) { return m.decodeText(sc, s) }, nil)
+}
+
+// decodeChecked 以 text 完成文本层、再解码 IDX 块；check 非 nil 时对解码结果做进一步检查，
+// 其错误作为最终结果上报并返回（此时仍返回 data 与 values）。
+func (m *IdMix) decodeChecked(op Operation, s string, text func(string) ([]byte, error), check func(data []byte, values []any) error) ([]byte, []any, error) {
+	var start time.Time
+	if m.observer != nil {
+		start = time.Now()
+	}
+	data, err := text(s)
+	if err != nil {
+		if m.observer != nil {
+			m.observeDecode(op, start, s, nil, err, true)
+		}
+		return nil, nil, err
+	}
+	values, err := m.decodeIdx(data)
+	if err == nil && check != nil {
+		err = check(data, values)
+	}
+	if m.observer != nil {
+		m.observeDecode(op, start, s, data, err, false)
+	}
+	return data, values, err
+}
+
+// ExpvarObserver 将事件计数发布到 expvar.Map，键为 "<op>.<class>"（如 "decode.checksum"），
+// 另有 "<op>.nanos" 累计耗时。
+type ExpvarObserver struct {
+	m *expvar.Map
+}
+
+// NewExpvarObserver 在 name 下发布计数（/debug/vars 可见）；name 已存在且为 *expvar.Map 时复用。
+func NewExpvarObserver(name string) (*ExpvarObserver, error) {
+	switch v := expvar.Get(name).(type) {
+	case nil:
+		return &ExpvarObserver{m: expvar.NewMap(name)}, nil
+	case *expvar.Map:
+		return &ExpvarObserver{m: v}, nil
+	default:
+		return nil, fmt.Errorf("expvar %q already published as %T", name, v)
+	}
+}
+
+// Map 返回底层的 expvar.Map。
+func (o *ExpvarObserver) Map() *expvar.Map { return o.m }
+
+func (o *ExpvarObserver) Observe(e Event) {
+	op := e.Op.String()
+	o.m.Add(op+"."+e.Class.String(), 1)
+	o.m.Add(op+".nanos", int64(e.Duration))
+}
+
+// SlogObserver 将事件写入 log/slog：成功用 SuccessLevel，失败用 FailureLevel。
+// 只记录长度与分类，不记录编码串本身（错误信息中可能带有出错的单个字符）。
+type SlogObserver struct {
+	Logger       *slog.Logger
+	SuccessLevel slog.Level
+	FailureLevel slog.Level
+}
+
+// NewSlogObserver 创建 slog 适配器，成功为 Debug、失败为 Warn；logger 为 nil 时使用 slog.Default()。
+func NewSlogObserver(logger *slog.Logger) *SlogObserver {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &SlogObserver{Logger: logger, SuccessLevel: slog.LevelDebug, FailureLevel: slog.LevelWarn}
+}
+
+func (o *SlogObserver) Observe(e Event) {
+	level := o.SuccessLevel
+	if e.Err != nil {
+		level = o.FailureLevel
+	}
+	ctx := context.Background()
+	if !o.Logger.Enabled(ctx, level) {
+		return
+	}
+	attrs := []slog.Attr{
+		slog.String("op", e.Op.String()),
+		slog.Duration("duration", e.Duration),
+		slog.Int("textLen", e.TextLen),
+		slog.Int("binaryLen", e.BinaryLen),
+		slog.Int("variant", e.Variant),
+		slog.String("class", e.Class.String()),
+	}
+	if e.Err != nil {
+		attrs = append(attrs, slog.String("error", e.Err.Error()))
+	}
+	o.Logger.LogAttrs(ctx, level, "idmix", attrs...)
+}
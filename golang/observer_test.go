@@ -0,0 +1,326 @@
+// observer_test.go 覆盖观测钩子：各操作与错误类别的上报、expvar 与 slog 适配器，以及未设置时的开销。
+package idmix
+
+import (
+	"bytes"
+	"errors"
+	"expvar"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recorder 记录收到的事件。
+type recorder struct {
+	mu     sync.Mutex
+	events []Event
+}
+
+func (r *recorder) Observe(e Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, e)
+}
+
+func (r *recorder) last(t *testing.T) Event {
+	t.Helper()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.events) == 0 {
+		t.Fatal("no event observed")
+	}
+	return r.events[len(r.events)-1]
+}
+
+// TestObserverEvents 成功的编解码上报操作、长度与 variant。
+func TestObserverEvents(t *testing.T) {
+	rec := &recorder{}
+	idx, _ := NewIdx(WithTagBits(1))
+	m, err := New(WithIdx(idx), WithObserver(rec))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, _ := m.EncodeWithVariant(9, uint32(1001), "eu")
+	e := rec.last(t)
+	if e.Op != OpEncode || e.Variant != 9 || e.TextLen != len(s) || e.BinaryLen == 0 || e.Class != ErrClassNone || e.Err != nil {
+		t.Fatalf("encode event: %+v", e)
+	}
+	binLen := e.BinaryLen
+
+	checks := []struct {
+		op  Operation
+		run func() error
+	}{
+		{OpDecode, func() error { _, err := m.Decode(s); return err }},
+		{OpInspect, func() error { _, err := m.Inspect(s); return err }},
+		{OpDecodeTagged, func() error { _, _, err := m.DecodeTagged(s); return err }},
+	}
+	for _, c := range checks {
+		if err := c.run(); err != nil {
+			t.Fatal(err)
+		}
+		e := rec.last(t)
+		if e.Op != c.op || e.Variant != 9 || e.TextLen != len(s) || e.BinaryLen != binLen || e.Class != ErrClassNone {
+			t.Fatalf("%s event: %+v", c.op, e)
+		}
+		t.Logf("%-12s %+v", c.op, e)
+	}
+
+	// 基于 Encode / Decode 的入口同样上报
+	n := len(rec.events)
+	if _, err := m.EncodeBatch([][]any{{uint8(1)}, {uint8(2)}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.EncodeTagged(1, uint8(3)); err != nil {
+		t.Fatal(err)
+	}
+	if got := len(rec.events) - n; got != 3 {
+		t.Fatalf("%d events for 3 encodes", got)
+	}
+	if _, err := New(WithObserver(nil)); err == nil {
+		t.Fatal("expected nil observer error")
+	}
+}
+
+// TestObserverErrorClasses 逐一构造各错误类别。
+func TestObserverErrorClasses(t *testing.T) {
+	rec := &recorder{}
+	m, _ := New(WithObserver(rec))
+	idx := m.Idx()
+	encodeRaw := func(data []byte) string {
+		s, err := m.Codec().Encode(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+	good, _ := idx.EncodeWithVariant(3, uint32(1001))
+	badCheck := append([]byte(nil), good...)
+	badCheck[0] ^= 1
+	badCount := rawBlock(idx, 0, []byte{0x80, 1}, []byte{0x05}) // 多对象 header 的 count 不能为 1
+	badVersion := []byte{0x80, extMarker, extHasVersion, 0x13, 0x05}
+
+	limited, _ := New(WithObserver(rec), WithDecodeLimits(DecodeLimits{MaxChars: 4}))
+	strict, _ := New(WithObserver(rec), WithStrict())
+	users := m.ForKind("user")
+	tagIdx, _ := NewIdx(WithTagBits(1))
+	tagged, _ := New(WithIdx(tagIdx), WithObserver(rec))
+
+	cases := []struct {
+		want ErrorClass
+		run  func() error
+	}{
+		{ErrClassMalformed, func() error { _, err := m.Decode("!!"); return err }},
+		{ErrClassChecksum, func() error { _, err := m.Decode(encodeRaw(badCheck)); return err }},
+		{ErrClassFormat, func() error { _, err := m.Decode(encodeRaw(badCount)); return err }},
+		{ErrClassVersion, func() error { _, err := m.Decode(encodeRaw(badVersion)); return err }},
+		{ErrClassLimit, func() error { _, err := limited.Decode("abcdefgh"); return err }},
+		{ErrClassNonCanonical, func() error { _, err := strict.Decode("a" + mustEncode(t, m, uint8(1))); return err }},
+		{ErrClassInvalidInput, func() error { _, err := m.Encode(3.14); return err }},
+		// 选定 variant 前即被拒绝的编码同样上报
+		{ErrClassInvalidInput, func() error { _, err := m.Encode(); return err }},
+		{ErrClassInvalidInput, func() error { _, err := tagged.EncodeTagged(2, uint8(1)); return err }},
+		{ErrClassInvalidInput, func() error { _, err := m.EncodeWithTTL(0, uint8(1)); return err }},
+		{ErrClassWrongKind, func() error {
+			// 2 位校验下约 1/4 的其他 kind 编码串会通过校验，取第一个被识别为 kind 不符的
+			for v := 0; v < 32; v++ {
+				s, _ := m.EncodeWithVariant(v, uint32(1001))
+				if _, err := users.Decode(s); errors.Is(err, ErrWrongKind) {
+					return err
+				}
+			}
+			return errors.New("no token rejected as another kind")
+		}},
+	}
+	for _, c := range cases {
+		err := c.run()
+		if err == nil {
+			t.Fatalf("%s: expected error", c.want)
+		}
+		e := rec.last(t)
+		if e.Class != c.want || e.Err == nil {
+			t.Fatalf("%s: got class %s (%v)", c.want, e.Class, e.Err)
+		}
+		if c.want == ErrClassMalformed && (e.Variant != -1 || e.BinaryLen != 0) {
+			t.Fatalf("malformed event should have no binary: %+v", e)
+		}
+		if e.Err != err {
+			t.Fatalf("%s: event error %v, returned %v", c.want, e.Err, err)
+		}
+		t.Logf("%-13s variant=%-2d bin=%d %v", e.Class, e.Variant, e.BinaryLen, e.Err)
+	}
+}
+
+// TestObserverDecodeEntryPoints 每个解码入口都以自己的操作上报一次，错误为入口最终返回的错误。
+func TestObserverDecodeEntryPoints(t *testing.T) {
+	rec := &recorder{}
+	t0 := time.Unix(1700000000, 0)
+	rs, err := NewRSCodec(AlphabetCrockford32, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, _ := New(WithObserver(rec), WithClock(func() time.Time { return t0 }))
+	rsm, _ := New(WithObserver(rec), WithCodec(rs))
+	rsStrict, _ := New(WithObserver(rec), WithCodec(rs), WithStrict())
+	other, _ := New()
+
+	s := mustEncode(t, m, uint32(1001))
+	timed, _ := m.EncodeWithTTL(time.Minute, uint32(1001))
+	fixed := mustEncode(t, rsm, uint32(1001))
+	typo := []rune(fixed)
+	typo[0] = []rune(AlphabetCrockford32)[strings.IndexRune(AlphabetCrockford32, typo[0])^1]
+
+	cases := []struct {
+		op   Operation
+		want ErrorClass
+		run  func() error
+	}{
+		{OpDecode, ErrClassNone, func() error { _, err := m.Decode(s); return err }},
+		{OpDecode, ErrClassNone, func() error { _, err := m.DecodeBatch([]string{s}); return err }},
+		{OpInspect, ErrClassNone, func() error { _, err := m.Inspect(s); return err }},
+		{OpDecodeTagged, ErrClassNone, func() error { _, _, err := m.DecodeTagged(s); return err }},
+		{OpDecodeValid, ErrClassNone, func() error { _, err := m.DecodeValidNow(timed); return err }},
+		{OpDecodeValid, ErrClassExpired, func() error { _, err := m.DecodeValid(timed, t0.Add(time.Hour)); return err }},
+		{OpDecodeValid, ErrClassNotYetValid, func() error { _, err := m.DecodeValid(timed, t0.Add(-time.Hour)); return err }},
+		{OpDecodeValid, ErrClassNoTTL, func() error { _, err := m.DecodeValid(s, t0); return err }},
+		{OpDecodeCorrected, ErrClassNone, func() error { _, _, err := rsm.DecodeCorrected(string(typo)); return err }},
+		{OpDecodeCorrected, ErrClassNonCanonical, func() error { _, _, err := rsStrict.DecodeCorrected(string(typo)); return err }},
+		{OpDecodeCorrected, ErrClassMalformed, func() error { _, _, err := rsm.DecodeCorrected("!!"); return err }},
+		{OpCanonical, ErrClassNone, func() error { _, err := m.Canonical(s); return err }},
+		{OpCanonical, ErrClassNone, func() error { _, err := m.Fingerprint(s); return err }},
+		{OpCanonical, ErrClassMalformed, func() error {
+			if m.Equal(s, "!!") {
+				return nil
+			}
+			return errors.New("not equal")
+		}},
+		{OpTranscode, ErrClassNone, func() error { _, err := Transcode(s, m, other); return err }},
+		{OpTranscode, ErrClassMalformed, func() error { _, err := Transcode("!!", m, other); return err }},
+	}
+	for _, c := range cases {
+		n := len(rec.events)
+		err := c.run()
+		if (err != nil) != (c.want != ErrClassNone) {
+			t.Fatalf("%s %s: returned %v", c.op, c.want, err)
+		}
+		e := rec.last(t)
+		if e.Op != c.op || e.Class != c.want || e.TextLen == 0 {
+			t.Fatalf("%s %s: got event %+v", c.op, c.want, e)
+		}
+		if c.op != OpCanonical && c.op != OpTranscode && e.Err != err {
+			t.Fatalf("%s: event error %v, returned %v", c.op, e.Err, err)
+		}
+		// Equal 对两个参数各上报一次
+		want := 1
+		if c.op == OpCanonical && c.want == ErrClassMalformed {
+			want = 2
+		}
+		if got := len(rec.events) - n; got != want {
+			t.Fatalf("%s %s: %d events, want %d", c.op, c.want, got, want)
+		}
+		t.Logf("%-15s %-12s variant=%-2d %v", e.Op, e.Class, e.Variant, e.Err)
+	}
+}
+
+// TestExpvarObserver 计数按 "<op>.<class>" 累加，同名复用。
+func TestExpvarObserver(t *testing.T) {
+	o, err := NewExpvarObserver("idmix_test_expvar")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, _ := New(WithObserver(o))
+	s := mustEncode(t, m, uint8(1))
+	for i := 0; i < 3; i++ {
+		m.Decode(s)
+	}
+	m.Decode("!!")
+	again, err := NewExpvarObserver("idmix_test_expvar")
+	if err != nil || again.Map() != o.Map() {
+		t.Fatalf("reuse: %v", err)
+	}
+	get := func(key string) string {
+		if v := o.Map().Get(key); v != nil {
+			return v.String()
+		}
+		return ""
+	}
+	if get("encode.ok") != "1" || get("decode.ok") != "3" || get("decode.malformed") != "1" || get("decode.nanos") == "" {
+		t.Fatalf("counters: %s", o.Map().String())
+	}
+	t.Logf("%s", o.Map().String())
+
+	expvar.NewInt("idmix_test_int")
+	if _, err := NewExpvarObserver("idmix_test_int"); err == nil {
+		t.Fatal("expected type conflict error")
+	}
+}
+
+// TestSlogObserver 失败按 FailureLevel 记录分类与错误，不记录编码串本身；级别未启用时不输出。
+func TestSlogObserver(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelInfo}))
+	m, _ := New(WithObserver(NewSlogObserver(logger)))
+	s := mustEncode(t, m, uint32(1001))
+	m.Decode(s)
+	if buf.Len() != 0 {
+		t.Fatalf("debug-level success should not be logged: %s", buf.String())
+	}
+	bad := s[:len(s)-1] + "!"
+	m.Decode(bad)
+	out := buf.String()
+	if !strings.Contains(out, "level=WARN") || !strings.Contains(out, "op=decode") || !strings.Contains(out, "class=malformed") || strings.Contains(out, bad) {
+		t.Fatalf("log: %s", out)
+	}
+	t.Logf("%s", strings.TrimSpace(out))
+}
+
+// BenchmarkObserver 对比未设置、空操作与各适配器下的 Decode 开销；
+// 未设置时与不带观测的实现一致（只多一次 nil 判断，0 次额外分配）。
+func BenchmarkObserver(b *testing.B) {
+	discard := slog.New(slog.NewTextHandler(&bytes.Buffer{}, &slog.HandlerOptions{Level: slog.LevelWarn}))
+	expvarObs, _ := NewExpvarObserver("idmix_bench_expvar")
+	for _, c := range []struct {
+		name string
+		opts []Option
+	}{
+		{"none", nil},
+		{"noop", []Option{WithObserver(ObserverFunc(func(Event) {}))}},
+		{"expvar", []Option{WithObserver(expvarObs)}},
+		{"slog_disabled", []Option{WithObserver(NewSlogObserver(discard))}},
+	} {
+		m, _ := New(c.opts...)
+		s := mustEncodeB(b, m, uint32(1001), "eu")
+		b.Run(c.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := m.Decode(s); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func mustEncodeB(b *testing.B, m *IdMix, values ...any) string {
+	b.Helper()
+	s, err := m.Encode(values...)
+	if err != nil {
+		b.Fatal(err)
+	}
+	return s
+}
+
+// TestObserverNoOverhead 未设置 Observer 时 Decode 的分配次数与设置空操作 Observer 时相同（不因观测额外分配）。
+func TestObserverNoOverhead(t *testing.T) {
+	plain, _ := New()
+	observed, _ := New(WithObserver(ObserverFunc(func(Event) {})))
+	s := mustEncode(t, plain, uint32(1001), "eu")
+	a := testing.AllocsPerRun(100, func() { plain.Decode(s) })
+	b := testing.AllocsPerRun(100, func() { observed.Decode(s) })
+	if a > b {
+		t.Fatalf("unobserved decode allocates more (%v) than observed (%v)", a, b)
+	}
+	t.Logf("allocs: none=%v noop=%v", a, b)
+}
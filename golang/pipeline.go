@@ -0,0 +1,198 @@
+// pipeline.go 提供字节层变换（Transform）与可组合的编解码流水线（Pipeline）。
+//
+// Codec 只负责二进制↔文本一步；压缩、异或、加密、校验等二进制↔二进制的步骤实现为 Transform，
+// 再由 Pipeline 与一个 Codec 组合：编码时按顺序执行各 Transform 后交给 Codec，解码时逆序还原。
+package idmix
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+)
+
+// Transform 二进制↔二进制的可逆变换（流水线中的一个字节层步骤）。
+type Transform interface {
+	Forward(data []byte) ([]byte, error)
+	Inverse(data []byte) ([]byte, error)
+}
+
+// FuncTransform 由函数实现的 Transform，便于包装 AES 等自定义逻辑。
+type FuncTransform struct {
+	ForwardFn func(data []byte) ([]byte, error)
+	InverseFn func(data []byte) ([]byte, error)
+}
+
+func (f FuncTransform) Forward(data []byte) ([]byte, error) {
+	if f.ForwardFn == nil {
+		return nil, errNilTransformFunc
+	}
+	return f.ForwardFn(data)
+}
+
+func (f FuncTransform) Inverse(data []byte) ([]byte, error) {
+	if f.InverseFn == nil {
+		return nil, errNilTransformFunc
+	}
+	return f.InverseFn(data)
+}
+
+var errNilTransformFunc = errors.New("transform function is nil")
+
+// PipelineCodec 由若干 Transform 与一个文本层 Codec 组成，本身也实现 Codec。
+type PipelineCodec struct {
+	codec      Codec
+	transforms []Transform
+}
+
+// Pipeline 组合 codec 与 transforms：编码时依次执行 transforms[0..n-1].Forward 再 codec.Encode，
+// 解码时 codec.Decode 后依次执行 transforms[n-1..0].Inverse。
+//
+// 例如 Pipeline(NewBase64URLCodec(), flate, xor) 即「压缩 → 加密 → 文本」。
+func Pipeline(codec Codec, transforms ...Transform) (*PipelineCodec, error) {
+	if codec == nil {
+		return nil, errors.New("codec cannot be nil")
+	}
+	for i, t := range transforms {
+		if t == nil {
+			return nil, fmt.Errorf("transform %d is nil", i)
+		}
+	}
+	return &PipelineCodec{codec: codec, transforms: append([]Transform(nil), transforms...)}, nil
+}
+
+// Codec 返回流水线末端的文本层 Codec。
+func (p *PipelineCodec) Codec() Codec { return p.codec }
+
+// Transforms 返回按编码顺序排列的 Transform（副本）。
+func (p *PipelineCodec) Transforms() []Transform {
+	return append([]Transform(nil), p.transforms...)
+}
+
+func (p *PipelineCodec) Encode(data []byte) (string, error) {
+	var err error
+	for i, t := range p.transforms {
+		if data, err = t.Forward(data); err != nil {
+			return "", fmt.Errorf("pipeline transform %d: %w", i, err)
+		}
+	}
+	return p.codec.Encode(data)
+}
+
+func (p *PipelineCodec) Decode(s string) ([]byte, error) {
+	data, err := p.codec.Decode(s)
+	if err != nil {
+		return nil, err
+	}
+	for i := len(p.transforms) - 1; i >= 0; i-- {
+		if data, err = p.transforms[i].Inverse(data); err != nil {
+			return nil, fmt.Errorf("pipeline transform %d: %w", i, err)
+		}
+	}
+	return data, nil
+}
+
+// NewXORTransform 将 key 循环异或到二进制上（自逆）。仅作轻量混淆，不是加密。
+func NewXORTransform(key []byte) (Transform, error) {
+	if len(key) == 0 {
+		return nil, errors.New("xor key cannot be empty")
+	}
+	key = append([]byte(nil), key...)
+	xor := func(data []byte) ([]byte, error) {
+		buf := make([]byte, len(data))
+		for i, b := range data {
+			buf[i] = b ^ key[i%len(key)]
+		}
+		return buf, nil
+	}
+	return FuncTransform{ForwardFn: xor, InverseFn: xor}, nil
+}
+
+// ErrInflateLimit 解压结果超过 FlateTransform 的上限。
+var ErrInflateLimit = errors.New("inflated data exceeds limit")
+
+// DefaultInflateLimit 为 NewFlateTransform 解压输出的默认上限（防止压缩炸弹）。
+const DefaultInflateLimit = 16 << 20
+
+// FlateTransform 使用 compress/flate（RFC 1951 原始 DEFLATE，无 zlib/gzip 头）压缩。
+//
+// 短 ID 通常因压缩头开销反而变长，适合较长的字符串或重复内容。
+type FlateTransform struct {
+	level int
+	limit int
+}
+
+// NewFlateTransform 创建压缩变换；level 取 flate.HuffmanOnly~flate.BestCompression，
+// 解压输出上限为 DefaultInflateLimit（可用 WithInflateLimit 调整）。
+func NewFlateTransform(level int) (*FlateTransform, error) {
+	if level < flate.HuffmanOnly || level > flate.BestCompression {
+		return nil, fmt.Errorf("invalid flate level %d", level)
+	}
+	return &FlateTransform{level: level, limit: DefaultInflateLimit}, nil
+}
+
+// WithInflateLimit 返回解压上限为 limit 字节的副本。
+func (f *FlateTransform) WithInflateLimit(limit int) (*FlateTransform, error) {
+	if limit < 1 {
+		return nil, fmt.Errorf("inflate limit must be positive, got %d", limit)
+	}
+	c := *f
+	c.limit = limit
+	return &c, nil
+}
+
+func (f *FlateTransform) Forward(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w, err := flate.NewWriter(&buf, f.level)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (f *FlateTransform) Inverse(data []byte) ([]byte, error) {
+	r := flate.NewReader(bytes.NewReader(data))
+	defer r.Close()
+	out, err := io.ReadAll(io.LimitReader(r, int64(f.limit)+1))
+	if err != nil {
+		return nil, fmt.Errorf("inflate: %w", err)
+	}
+	if len(out) > f.limit {
+		return nil, fmt.Errorf("%w of %d bytes", ErrInflateLimit, f.limit)
+	}
+	return out, nil
+}
+
+// ErrTransformChecksum ChecksumTransform 校验失败（数据被篡改或截断）。
+var ErrTransformChecksum = errors.New("transform checksum mismatch")
+
+// ChecksumTransform 在数据末尾追加 4 字节大端 CRC-32（IEEE），解码时校验并去除。
+//
+// 与 WithChecksum 不同，它作用于整个流水线中间结果（如压缩或异或之后），不改变 IDX 格式。
+type ChecksumTransform struct{}
+
+func NewChecksumTransform() ChecksumTransform { return ChecksumTransform{} }
+
+func (ChecksumTransform) Forward(data []byte) ([]byte, error) {
+	return binary.BigEndian.AppendUint32(append([]byte(nil), data...), crc32.ChecksumIEEE(data)), nil
+}
+
+func (ChecksumTransform) Inverse(data []byte) ([]byte, error) {
+	if len(data) < crc32.Size {
+		return nil, fmt.Errorf("%w: %d bytes is shorter than the checksum", ErrTransformChecksum, len(data))
+	}
+	n := len(data) - crc32.Size
+	if crc32.ChecksumIEEE(data[:n]) != binary.BigEndian.Uint32(data[n:]) {
+		return nil, ErrTransformChecksum
+	}
+	return data[:n], nil
+}
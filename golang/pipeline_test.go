@@ -0,0 +1,170 @@
+// pipeline_test.go 覆盖 Transform 组合顺序、内置 XOR / flate / 校验变换，以及流水线在 IdMix 中的使用。
+package idmix
+
+import (
+	"bytes"
+	"compress/flate"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// TestPipelineOrder 编码按 transforms 顺序执行，解码逆序；顺序不同则输出不同。
+func TestPipelineOrder(t *testing.T) {
+	var trace []string
+	step := func(name string) Transform {
+		return FuncTransform{
+			ForwardFn: func(data []byte) ([]byte, error) {
+				trace = append(trace, name+".fwd")
+				return append(data, name[0]), nil
+			},
+			InverseFn: func(data []byte) ([]byte, error) {
+				trace = append(trace, name+".inv")
+				if len(data) == 0 || data[len(data)-1] != name[0] {
+					return nil, errors.New("wrong order")
+				}
+				return data[:len(data)-1], nil
+			},
+		}
+	}
+	p, err := Pipeline(NewBase64URLCodec(), step("a"), step("b"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, _ := p.Encode([]byte("x"))
+	if want, _ := NewBase64URLCodec().Encode([]byte("xab")); s != want {
+		t.Fatalf("encode %q", s)
+	}
+	back, err := p.Decode(s)
+	if err != nil || string(back) != "x" {
+		t.Fatalf("decode %q %v", back, err)
+	}
+	if got := strings.Join(trace, ","); got != "a.fwd,b.fwd,b.inv,a.inv" {
+		t.Fatalf("trace %s", got)
+	}
+	t.Logf("trace: %v", trace)
+
+	if _, err := Pipeline(nil); err == nil {
+		t.Fatal("expected nil codec error")
+	}
+	if _, err := Pipeline(NewBase64Codec(), nil); err == nil {
+		t.Fatal("expected nil transform error")
+	}
+	if _, err := (FuncTransform{}).Forward(nil); err == nil {
+		t.Fatal("expected nil function error")
+	}
+}
+
+// TestXORCodecCompat NewXORCodec 改为流水线实现后输出不变。
+func TestXORCodecCompat(t *testing.T) {
+	key := []byte{0x5A, 0x17}
+	c, err := NewXORCodec(NewBase64Codec(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, _ := c.Encode([]byte{0x00, 0x01, 0x02})
+	want, _ := NewBase64Codec().Encode([]byte{0x5A, 0x16, 0x58})
+	if s != want {
+		t.Fatalf("got %q, want %q", s, want)
+	}
+	if _, err := NewXORTransform(nil); err == nil {
+		t.Fatal("expected empty key error")
+	}
+}
+
+// TestPipelineCompressEncrypt 「压缩 → 异或 → 校验 → 文本」流水线作为 IdMix 文本层。
+func TestPipelineCompressEncrypt(t *testing.T) {
+	fl, err := NewFlateTransform(flate.BestCompression)
+	if err != nil {
+		t.Fatal(err)
+	}
+	xor, _ := NewXORTransform([]byte("secret"))
+	p, err := Pipeline(NewBase64URLCodec(), fl, xor, NewChecksumTransform())
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := New(WithCodec(p))
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain, _ := New(WithCodec(NewBase64URLCodec()))
+	long := strings.Repeat("order-item;", 5)
+	values := []any{uint32(1001), long, long, long, long}
+	s, err := m.Encode(values...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref, _ := plain.Encode(values...)
+	if len(s) >= len(ref) {
+		t.Fatalf("compressed %d chars, plain %d", len(s), len(ref))
+	}
+	t.Logf("plain %d chars, flate+xor+crc32 %d chars", len(ref), len(s))
+	list, err := m.Decode(s)
+	if err != nil || list[4].(string) != long {
+		t.Fatalf("decode %v %v", list, err)
+	}
+
+	// 改动任一字符都会被校验变换拒绝（或导致 Base64 解码失败）；
+	// 末尾字符的未用低位不影响 Base64 解码结果，这类改动不计入
+	orig, _ := NewBase64URLCodec().Decode(s)
+	edits, rejected := 0, 0
+	for i := 0; i < len(s); i++ {
+		b := []byte(s)
+		b[i] ^= 1
+		if raw, err := NewBase64URLCodec().Decode(string(b)); err == nil && bytes.Equal(raw, orig) {
+			continue
+		}
+		edits++
+		if _, err := m.Decode(string(b)); err != nil {
+			rejected++
+		}
+	}
+	if rejected != edits {
+		t.Fatalf("only %d/%d single-character edits rejected", rejected, edits)
+	}
+}
+
+func TestFlateTransform(t *testing.T) {
+	if _, err := NewFlateTransform(10); err == nil {
+		t.Fatal("expected invalid level error")
+	}
+	fl, _ := NewFlateTransform(flate.DefaultCompression)
+	data := bytes.Repeat([]byte{0}, 4096)
+	z, _ := fl.Forward(data)
+	back, err := fl.Inverse(z)
+	if err != nil || !bytes.Equal(back, data) {
+		t.Fatalf("round trip: %v", err)
+	}
+	t.Logf("4096 zero bytes => %d deflated bytes", len(z))
+
+	small, _ := fl.WithInflateLimit(4095)
+	if _, err := small.Inverse(z); !errors.Is(err, ErrInflateLimit) {
+		t.Fatalf("limit: %v", err)
+	}
+	if _, err := fl.WithInflateLimit(0); err == nil {
+		t.Fatal("expected invalid limit error")
+	}
+	if _, err := fl.Inverse([]byte{0xff, 0xff}); err == nil {
+		t.Fatal("expected corrupt input error")
+	}
+}
+
+func TestChecksumTransform(t *testing.T) {
+	c := NewChecksumTransform()
+	// CRC-32/IEEE("123456789") = 0xCBF43926
+	out, _ := c.Forward([]byte("123456789"))
+	if !bytes.Equal(out[9:], []byte{0xCB, 0xF4, 0x39, 0x26}) {
+		t.Fatalf("crc %x", out[9:])
+	}
+	back, err := c.Inverse(out)
+	if err != nil || string(back) != "123456789" {
+		t.Fatalf("inverse %q %v", back, err)
+	}
+	out[0] ^= 1
+	if _, err := c.Inverse(out); !errors.Is(err, ErrTransformChecksum) {
+		t.Fatalf("tampered: %v", err)
+	}
+	if _, err := c.Inverse([]byte{1, 2}); !errors.Is(err, ErrTransformChecksum) {
+		t.Fatalf("short: %v", err)
+	}
+}
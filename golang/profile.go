@@ -0,0 +1,431 @@
+// profile.go 提供可序列化的命名配置（Profile）与注册表，
+// 使各服务从同一份 JSON 构造完全一致的 IdMix，避免手写 Option 组合逐渐漂移。
+//
+// 密钥不写入 Profile，只保存引用名（KeyRef），构造时由 KeyResolver 解析。
+package idmix
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+)
+
+// 内置 Codec 类型名（Profile.Codec）。
+const (
+	CodecRadix        = "radix"
+	CodecCompactRadix = "compactRadix"
+	CodecChunkedRadix = "chunkedRadix"
+	CodecBase64       = "base64"
+	CodecBase64URL    = "base64url"
+	CodecBase32       = "base32"
+	CodecCrockford32  = "crockford32"
+	CodecBase58       = "base58"
+	CodecZ85          = "z85"
+)
+
+// Profile 描述一个 IdMix 的全部配置；零值字段使用库默认值。
+type Profile struct {
+	Name string `json:"name,omitempty"`
+	// Codec 为文本层类型，默认 "radix"。
+	Codec string `json:"codec,omitempty"`
+	// Alphabet 为 radix 字符表，默认 DefaultAlphabet。
+	Alphabet    string `json:"alphabet,omitempty"`
+	CheckBits   int    `json:"checkBits,omitempty"`
+	MaxVariants int    `json:"maxVariants,omitempty"`
+	MaxObjects  int    `json:"maxObjects,omitempty"`
+	// TagBits 为 variant_id 中 tag 所占位数（见 EncodeTagged），maxVariants 须能被 2^tagBits 整除。
+	TagBits int `json:"tagBits,omitempty"`
+	// Checksum 为校验方式名（xor、crc8、crc16，见 WithChecksum），默认 xor。
+	Checksum string `json:"checksum,omitempty"`
+	// Version 为编码写入的 IDX 版本标记（如 "v1.2"，见 WithVersion），默认不写。
+	Version string `json:"version,omitempty"`
+	// Strict 启用严格解码，只接受规范形式（见 WithStrict）。
+	Strict bool `json:"strict,omitempty"`
+	// AlphabetChecks 为字符表校验名（strictURLSafe、noConfusables、nfcStable），仅 radix 有效。
+	AlphabetChecks []string `json:"alphabetChecks,omitempty"`
+	// AlphabetKeyRef 为字符表打乱种子的引用名（见 ShuffleAlphabet），仅 radix 有效。
+	AlphabetKeyRef string `json:"alphabetKeyRef,omitempty"`
+	// KeyRef 为 XOR 密钥的引用名，文本编码前将密钥循环异或到二进制块上。
+	KeyRef string `json:"keyRef,omitempty"`
+}
+
+// ProfileError 指出 Profile 中出错的字段。
+type ProfileError struct {
+	Profile string
+	Field   string
+	Err     error
+}
+
+func (e *ProfileError) Error() string {
+	if e.Profile == "" {
+		return fmt.Sprintf("profile field %q: %v", e.Field, e.Err)
+	}
+	return fmt.Sprintf("profile %q field %q: %v", e.Profile, e.Field, e.Err)
+}
+
+func (e *ProfileError) Unwrap() error { return e.Err }
+
+// KeyResolver 按引用名返回密钥。
+type KeyResolver func(ref string) ([]byte, error)
+
+// KeyMap 是基于内存 map 的 KeyResolver。
+type KeyMap map[string][]byte
+
+// Resolve 实现 KeyResolver。
+func (km KeyMap) Resolve(ref string) ([]byte, error) {
+	key, ok := km[ref]
+	if !ok {
+		return nil, fmt.Errorf("unknown key %q", ref)
+	}
+	return key, nil
+}
+
+// LoadKeyMap 从 {"ref": "<hex>"} 格式的 JSON 读取密钥。
+func LoadKeyMap(rd io.Reader) (KeyMap, error) {
+	var hexKeys map[string]string
+	if err := json.NewDecoder(rd).Decode(&hexKeys); err != nil {
+		return nil, err
+	}
+	keys := make(KeyMap, len(hexKeys))
+	for ref, h := range hexKeys {
+		key, err := hex.DecodeString(h)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", ref, err)
+		}
+		keys[ref] = key
+	}
+	return keys, nil
+}
+
+// LoadKeyMapFile 读取 LoadKeyMap 格式的密钥文件；path 为空时返回空 KeyMap。
+func LoadKeyMapFile(path string) (KeyMap, error) {
+	if path == "" {
+		return KeyMap{}, nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	keys, err := LoadKeyMap(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return keys, nil
+}
+
+// profileCodecs 将 Profile.Codec 映射到构造函数；返回的错误为指向出错字段的 *ProfileError。
+var profileCodecs = map[string]func(p Profile) (Codec, error){
+	CodecRadix: func(p Profile) (Codec, error) {
+		return profileRadixCodec(p, NewRadixCodec)
+	},
+	CodecCompactRadix: func(p Profile) (Codec, error) {
+		return profileRadixCodec(p, NewCompactRadixCodec)
+	},
+	CodecChunkedRadix: func(p Profile) (Codec, error) {
+		return profileRadixCodec(p, NewChunkedRadixCodec)
+	},
+	CodecBase64:      profileFixedCodec(NewBase64Codec()),
+	CodecBase64URL:   profileFixedCodec(NewBase64URLCodec()),
+	CodecBase32:      profileFixedCodec(NewBase32Codec()),
+	CodecCrockford32: profileFixedCodec(NewCrockfordCodec()),
+	CodecBase58:      profileFixedCodec(NewBase58Codec()),
+	CodecZ85:         profileFixedCodec(NewZ85Codec()),
+}
+
+// profileFixedCodec 用于字符表固定的标准编码，拒绝 radix 专属字段。
+func profileFixedCodec(c Codec) func(p Profile) (Codec, error) {
+	return func(p Profile) (Codec, error) {
+		if p.Alphabet != "" {
+			return nil, p.fieldErr("alphabet", errors.New("alphabet is only valid for radix codecs"))
+		}
+		if len(p.AlphabetChecks) > 0 {
+			return nil, p.fieldErr("alphabetChecks", errors.New("alphabetChecks is only valid for radix codecs"))
+		}
+		return c, nil
+	}
+}
+
+func profileRadixCodec(p Profile, build func(string, ...AlphabetCheck) (*RadixCodec, error)) (Codec, error) {
+	alphabet := p.Alphabet
+	if alphabet == "" {
+		alphabet = DefaultAlphabet
+	}
+	var checks []AlphabetCheck
+	for _, name := range p.AlphabetChecks {
+		c, err := ParseAlphabetCheck(name)
+		if err != nil {
+			return nil, p.fieldErr("alphabetChecks", err)
+		}
+		checks = append(checks, c)
+	}
+	rc, err := build(alphabet, checks...)
+	if err != nil {
+		return nil, p.fieldErr("alphabet", err)
+	}
+	return rc, nil
+}
+
+func (p Profile) fieldErr(field string, err error) error {
+	return &ProfileError{Profile: p.Name, Field: field, Err: err}
+}
+
+// profileIdxField 关联 Profile 字段名与对应的 IdxOption，便于错误定位。
+type profileIdxField struct {
+	field string
+	set   bool
+	opt   IdxOption
+}
+
+func (p Profile) idxOptions() []profileIdxField {
+	return []profileIdxField{
+		{"checkBits", p.CheckBits != 0, WithCheckBits(p.CheckBits)},
+		{"maxVariants", p.MaxVariants != 0, WithMaxVariants(p.MaxVariants)},
+		{"maxObjects", p.MaxObjects != 0, WithMaxObjects(p.MaxObjects)},
+		{"tagBits", p.TagBits != 0, WithTagBits(p.TagBits)},
+		{"checksum", p.Checksum != "", withChecksumName(p.Checksum)},
+		{"version", p.Version != "", withVersionName(p.Version)},
+	}
+}
+
+func withVersionName(name string) IdxOption {
+	return func(idx *Idx) error {
+		v, err := ParseIdxVersion(name)
+		if err != nil {
+			return err
+		}
+		return WithVersion(v)(idx)
+	}
+}
+
+func withChecksumName(name string) IdxOption {
+	return func(idx *Idx) error {
+		mode, err := ParseChecksumMode(name)
+		if err != nil {
+			return err
+		}
+		return WithChecksum(mode)(idx)
+	}
+}
+
+func (p Profile) codecKind() string {
+	if p.Codec == "" {
+		return CodecRadix
+	}
+	return p.Codec
+}
+
+// Validate 检查所有字段，返回的错误可用 errors.As 取得 *ProfileError。
+// 多个字段出错时以 errors.Join 合并。
+func (p Profile) Validate() error {
+	var errs []error
+	probe := &Idx{maxVariants: 32}
+	for _, o := range p.idxOptions() {
+		if !o.set {
+			continue
+		}
+		if err := o.opt(probe); err != nil {
+			errs = append(errs, p.fieldErr(o.field, err))
+		}
+	}
+	if len(errs) == 0 {
+		if err := validateTagSplit(probe.maxVariants, probe.tagBits); err != nil {
+			errs = append(errs, p.fieldErr("tagBits", err))
+		}
+	}
+	var codec Codec
+	build, ok := profileCodecs[p.codecKind()]
+	if !ok {
+		errs = append(errs, p.fieldErr("codec", fmt.Errorf("unknown codec %q", p.Codec)))
+	} else if c, err := build(p); err != nil {
+		errs = append(errs, err)
+	} else {
+		codec = c
+	}
+	if _, isRadix := codec.(*RadixCodec); p.AlphabetKeyRef != "" && codec != nil && !isRadix {
+		errs = append(errs, p.fieldErr("alphabetKeyRef", errors.New("alphabetKeyRef is only valid for radix codecs")))
+	}
+	return errors.Join(errs...)
+}
+
+// NewFromProfile 按 Profile 构造 IdMix；keys 仅在 KeyRef 非空时使用。
+func NewFromProfile(p Profile, keys KeyResolver) (*IdMix, error) {
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	var idxOpts []IdxOption
+	for _, o := range p.idxOptions() {
+		if o.set {
+			idxOpts = append(idxOpts, o.opt)
+		}
+	}
+	idx, err := NewIdx(idxOpts...)
+	if err != nil {
+		return nil, err
+	}
+	codec, err := profileCodecs[p.codecKind()](p)
+	if err != nil {
+		return nil, err
+	}
+	if p.AlphabetKeyRef != "" {
+		seed, err := p.resolveKey(keys, "alphabetKeyRef", p.AlphabetKeyRef)
+		if err != nil {
+			return nil, err
+		}
+		rc := codec.(*RadixCodec)
+		if codec, err = rc.withAlphabet(ShuffleAlphabet(rc.Alphabet(), seed)); err != nil {
+			return nil, p.fieldErr("alphabetKeyRef", err)
+		}
+	}
+	if p.KeyRef != "" {
+		key, err := p.resolveKey(keys, "keyRef", p.KeyRef)
+		if err != nil {
+			return nil, err
+		}
+		codec, err = NewXORCodec(codec, key)
+		if err != nil {
+			return nil, p.fieldErr("keyRef", err)
+		}
+	}
+	opts := []Option{WithIdx(idx), WithCodec(codec)}
+	if p.Strict {
+		opts = append(opts, WithStrict())
+	}
+	return New(opts...)
+}
+
+func (p Profile) resolveKey(keys KeyResolver, field, ref string) ([]byte, error) {
+	if keys == nil {
+		return nil, p.fieldErr(field, errors.New("no key resolver configured"))
+	}
+	key, err := keys(ref)
+	if err != nil {
+		return nil, p.fieldErr(field, err)
+	}
+	if len(key) == 0 {
+		return nil, p.fieldErr(field, fmt.Errorf("key %q is empty", ref))
+	}
+	return key, nil
+}
+
+// ProfileRegistry 保存命名 Profile 及其构造好的 IdMix，可并发读取。
+type ProfileRegistry struct {
+	keys      KeyResolver
+	mu        sync.RWMutex
+	profiles  map[string]Profile
+	instances map[string]*IdMix
+}
+
+// NewProfileRegistry 创建空注册表；keys 用于解析 Profile.KeyRef，可为 nil。
+func NewProfileRegistry(keys KeyResolver) *ProfileRegistry {
+	return &ProfileRegistry{
+		keys:      keys,
+		profiles:  make(map[string]Profile),
+		instances: make(map[string]*IdMix),
+	}
+}
+
+// Register 校验并构造 Profile，同名时覆盖。
+func (r *ProfileRegistry) Register(p Profile) error {
+	if p.Name == "" {
+		return p.fieldErr("name", errors.New("name is required"))
+	}
+	m, err := NewFromProfile(p, r.keys)
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.profiles[p.Name] = p
+	r.instances[p.Name] = m
+	return nil
+}
+
+// Get 返回命名 Profile 对应的 IdMix。
+func (r *ProfileRegistry) Get(name string) (*IdMix, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	m, ok := r.instances[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown profile %q", name)
+	}
+	return m, nil
+}
+
+// Profile 返回命名 Profile 的配置。
+func (r *ProfileRegistry) Profile(name string) (Profile, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	p, ok := r.profiles[name]
+	return p, ok
+}
+
+// Names 返回已注册的 Profile 名（升序）。
+func (r *ProfileRegistry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.profiles))
+	for name := range r.profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// profileFile 为配置文件格式：{"profiles": {"name": {...}}}，键即 Profile 名。
+type profileFile struct {
+	Profiles map[string]Profile `json:"profiles"`
+}
+
+// LoadProfiles 从 JSON 读取并注册全部 Profile（任一失败即返回错误）。
+func LoadProfiles(rd io.Reader, keys KeyResolver) (*ProfileRegistry, error) {
+	var f profileFile
+	dec := json.NewDecoder(rd)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&f); err != nil {
+		return nil, err
+	}
+	if len(f.Profiles) == 0 {
+		return nil, errors.New("no profiles defined")
+	}
+	r := NewProfileRegistry(keys)
+	names := make([]string, 0, len(f.Profiles))
+	for name := range f.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		p := f.Profiles[name]
+		if p.Name != "" && p.Name != name {
+			return nil, p.fieldErr("name", fmt.Errorf("does not match key %q", name))
+		}
+		p.Name = name
+		if err := r.Register(p); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// LoadProfilesFile 读取 path 的配置文件并注册全部 Profile，keyRef 由 keysPath 的密钥文件解析（见 LoadKeyMapFile，可为空）。
+func LoadProfilesFile(path, keysPath string) (*ProfileRegistry, error) {
+	keys, err := LoadKeyMapFile(keysPath)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	r, err := LoadProfiles(f, keys.Resolve)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return r, nil
+}
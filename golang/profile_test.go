@@ -0,0 +1,233 @@
+// profile_test.go 覆盖 Profile 构造、字段级校验错误与注册表加载。
+package idmix
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestProfileMatchesOptions Profile 构造结果与手写 Option 组合编码一致。
+func TestProfileMatchesOptions(t *testing.T) {
+	p := Profile{Name: "orders", Alphabet: "0123456789abcdef", CheckBits: 1, MaxVariants: 8, MaxObjects: 4}
+	b, err := json.Marshal(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var back Profile
+	if err := json.Unmarshal(b, &back); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(back, p) {
+		t.Fatalf("json round-trip: got %+v, want %+v", back, p)
+	}
+	t.Logf("Profile JSON: %s", b)
+
+	fromProfile, err := NewFromProfile(back, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	idx, err := NewIdx(WithCheckBits(1), WithMaxVariants(8), WithMaxObjects(4))
+	if err != nil {
+		t.Fatal(err)
+	}
+	manual, err := New(WithIdx(idx), WithAlphabet("0123456789abcdef"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for v := 0; v < 8; v++ {
+		a, err := fromProfile.EncodeWithVariant(v, uint32(1001), "eu")
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := manual.EncodeWithVariant(v, uint32(1001), "eu")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if a != b {
+			t.Fatalf("variant %d: profile %q, options %q", v, a, b)
+		}
+	}
+	if fromProfile.Idx().MaxObjects() != 4 {
+		t.Fatalf("maxObjects = %d", fromProfile.Idx().MaxObjects())
+	}
+}
+
+// TestProfileValidationFields 校验错误指向出错字段，多个字段同时报告。
+func TestProfileValidationFields(t *testing.T) {
+	cases := []struct {
+		p      Profile
+		fields []string
+	}{
+		{Profile{CheckBits: 3}, []string{"checkBits"}},
+		{Profile{MaxVariants: 33}, []string{"maxVariants"}},
+		{Profile{MaxObjects: 256}, []string{"maxObjects"}},
+		{Profile{TagBits: 6}, []string{"tagBits"}},
+		{Profile{MaxVariants: 12, TagBits: 3}, []string{"tagBits"}},
+		{Profile{Checksum: "crc32"}, []string{"checksum"}},
+		{Profile{Alphabet: "abca"}, []string{"alphabet"}},
+		{Profile{Codec: "base64", Alphabet: "abc"}, []string{"alphabet"}},
+		{Profile{Codec: "rot13"}, []string{"codec"}},
+		{Profile{AlphabetChecks: []string{"noConfusables"}}, []string{"alphabet"}},
+		{Profile{AlphabetChecks: []string{"strict"}}, []string{"alphabetChecks"}},
+		{Profile{Alphabet: AlphabetCrockford32, AlphabetChecks: []string{"nfcStable", ""}}, []string{"alphabetChecks"}},
+		{Profile{Codec: CodecBase58, AlphabetChecks: []string{"strictURLSafe"}}, []string{"alphabetChecks"}},
+		{Profile{Codec: CodecBase64, AlphabetKeyRef: "k"}, []string{"alphabetKeyRef"}},
+		{Profile{Name: "x", CheckBits: 5, Alphabet: "a"}, []string{"checkBits", "alphabet"}},
+	}
+	for _, c := range cases {
+		err := c.p.Validate()
+		if err == nil {
+			t.Fatalf("%+v: expected error", c.p)
+		}
+		for _, f := range c.fields {
+			if !strings.Contains(err.Error(), `field "`+f+`"`) {
+				t.Fatalf("%+v: error %q does not mention %s", c.p, err, f)
+			}
+		}
+		var pe *ProfileError
+		if !errors.As(err, &pe) || pe.Field != c.fields[0] {
+			t.Fatalf("%+v: errors.As got %+v", c.p, pe)
+		}
+		t.Logf("%+v => %v", c.p, err)
+	}
+	if err := (Profile{Alphabet: AlphabetNoLookalike55, AlphabetChecks: []string{"strictURLSafe", "noConfusables"}}).Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if err := (Profile{Checksum: "crc16", TagBits: 1}).Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if err := (Profile{Codec: CodecBase64}).Validate(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestLoadProfiles 从 JSON 加载注册表，keyRef 经 KeyResolver 解析。
+func TestLoadProfiles(t *testing.T) {
+	keys := KeyMap{"k1": {0x5A, 0x17}}
+	src := `{"profiles": {
+		"users": {"maxVariants": 4},
+		"vouchers": {"codec": "base64", "keyRef": "k1"}
+	}}`
+	r, err := LoadProfiles(strings.NewReader(src), keys.Resolve)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(r.Names(), ","); got != "users,vouchers" {
+		t.Fatalf("names = %s", got)
+	}
+	p, ok := r.Profile("vouchers")
+	if !ok || p.Name != "vouchers" || p.KeyRef != "k1" {
+		t.Fatalf("profile = %+v", p)
+	}
+	m, err := r.Get("vouchers")
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain, err := New(WithCodec(NewBase64Codec()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyed, _ := m.EncodeWithVariant(0, uint32(7))
+	unkeyed, _ := plain.EncodeWithVariant(0, uint32(7))
+	if keyed == unkeyed {
+		t.Fatal("keyRef should change the output")
+	}
+	list, err := m.Decode(keyed)
+	if err != nil || list[0].(uint32) != 7 {
+		t.Fatalf("decode: %v %v", list, err)
+	}
+	if _, err := r.Get("missing"); err == nil {
+		t.Fatal("expected unknown profile error")
+	}
+
+	shuffled, err := NewFromProfile(Profile{Alphabet: AlphabetCrockford32, AlphabetKeyRef: "k1"}, keys.Resolve)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := shuffled.Codec().(*RadixCodec).Alphabet(); got != ShuffleAlphabet(AlphabetCrockford32, keys["k1"]) {
+		t.Fatalf("alphabetKeyRef alphabet %q", got)
+	}
+	if _, err := NewFromProfile(Profile{AlphabetKeyRef: "k1"}, nil); err == nil {
+		t.Fatal("expected missing key resolver error")
+	}
+
+	bad := []struct {
+		name  string
+		src   string
+		field string
+	}{
+		{"unknown_key", `{"profiles": {"a": {"keyRef": "nope"}}}`, "keyRef"},
+		{"name_mismatch", `{"profiles": {"a": {"name": "b"}}}`, "name"},
+		{"bad_field", `{"profiles": {"a": {"maxObjects": 0, "checkBits": 9}}}`, "checkBits"},
+	}
+	for _, c := range bad {
+		_, err := LoadProfiles(strings.NewReader(c.src), keys.Resolve)
+		var pe *ProfileError
+		if !errors.As(err, &pe) || pe.Field != c.field {
+			t.Fatalf("%s: got %v", c.name, err)
+		}
+		t.Logf("%s => %v", c.name, err)
+	}
+	if _, err := LoadProfiles(strings.NewReader(`{"profiles": {"a": {"alphabett": "x"}}}`), nil); err == nil {
+		t.Fatal("expected unknown field error")
+	}
+}
+
+// TestLoadKeyMap 解析 hex 密钥文件，非法 JSON 与非法 hex 报错。
+func TestLoadKeyMap(t *testing.T) {
+	keys, err := LoadKeyMap(strings.NewReader(`{"k1": "5a17", "k2": ""}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(keys["k1"], []byte{0x5A, 0x17}) || len(keys) != 2 {
+		t.Fatalf("keys = %v", keys)
+	}
+	if _, err := LoadKeyMap(strings.NewReader(`["k1"]`)); err == nil {
+		t.Fatal("expected json error")
+	}
+	_, err = LoadKeyMap(strings.NewReader(`{"bad": "zz"}`))
+	if err == nil || !strings.Contains(err.Error(), `key "bad"`) {
+		t.Fatalf("err = %v", err)
+	}
+	t.Logf("keys: %v", keys)
+}
+
+// TestLoadProfilesFile 从配置文件与密钥文件加载，错误信息带文件路径。
+func TestLoadProfilesFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "profiles.json")
+	keysPath := filepath.Join(dir, "keys.json")
+	if err := os.WriteFile(path, []byte(`{"profiles": {"orders": {"keyRef": "k1"}}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keysPath, []byte(`{"k1": "5a17"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	r, err := LoadProfilesFile(path, keysPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Get("orders"); err != nil {
+		t.Fatal(err)
+	}
+	// 未提供密钥文件时 keyRef 无法解析
+	if _, err := LoadProfilesFile(path, ""); err == nil || !strings.Contains(err.Error(), path) {
+		t.Fatalf("err = %v", err)
+	}
+	if keys, err := LoadKeyMapFile(""); err != nil || len(keys) != 0 {
+		t.Fatalf("empty path: %v %v", keys, err)
+	}
+	if err := os.WriteFile(keysPath, []byte(`{"k1": "zz"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	_, err = LoadProfilesFile(path, keysPath)
+	if err == nil || !strings.Contains(err.Error(), keysPath) {
+		t.Fatalf("err = %v", err)
+	}
+	t.Logf("%v", err)
+}
@@ -0,0 +1,78 @@
+// random.go 管理 Encode 选取 variant 所用的随机数来源。
+//
+// 默认使用 math/rand/v2 的全局函数（运行时按线程维护 ChaCha8 状态，多 goroutine 并发时无锁）；
+// 需要可复现的输出时用 WithRandSource 提供带种子的来源，需要外部熵时用 WithRandReader。
+// variant 只用于打散编码串，不提供保密性，默认来源不需要密码学强度。
+package idmix
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"sync"
+)
+
+// intnFunc 返回 [0, n) 内的随机整数。
+type intnFunc func(n int) (int, error)
+
+// defaultIntn 为默认来源：math/rand/v2 全局函数，无锁。
+func defaultIntn(n int) (int, error) { return rand.IntN(n), nil }
+
+// WithRandSource 使用 src 选取 variant（如 rand.NewPCG(seed1, seed2)），相同种子产生相同的编码序列。
+//
+// rand.Source 本身不是并发安全的，实例以互斥锁保护 src；高并发下请使用默认来源。
+// ForKind 派生的实例共享同一个 src。
+func WithRandSource(src rand.Source) Option {
+	return func(m *IdMix) error {
+		if src == nil {
+			return errors.New("rand source cannot be nil")
+		}
+		r := rand.New(src)
+		var mu sync.Mutex
+		m.intn = func(n int) (int, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			return r.IntN(n), nil
+		}
+		return nil
+	}
+}
+
+// WithRandReader 从 r 读取随机字节选取 variant（如 crypto/rand.Reader），以拒绝采样保证均匀。
+//
+// 读取以互斥锁串行化；r 返回错误时 Encode 失败并返回该错误。
+func WithRandReader(r io.Reader) Option {
+	return func(m *IdMix) error {
+		if r == nil {
+			return errors.New("rand reader cannot be nil")
+		}
+		var mu sync.Mutex
+		var buf [1]byte
+		m.intn = func(n int) (int, error) {
+			if n < 1 || n > 256 {
+				return 0, fmt.Errorf("rand reader: n %d out of range [1, 256]", n)
+			}
+			limit := 256 - 256%n // 拒绝 [limit, 256)，使 b % n 均匀
+			mu.Lock()
+			defer mu.Unlock()
+			for {
+				if _, err := io.ReadFull(r, buf[:]); err != nil {
+					return 0, fmt.Errorf("rand reader: %w", err)
+				}
+				if int(buf[0]) < limit {
+					return int(buf[0]) % n, nil
+				}
+			}
+		}
+		return nil
+	}
+}
+
+// randIntn 以实例的随机来源返回 [0, n) 内的整数。
+func (m *IdMix) randIntn(n int) (int, error) {
+	if m.intn == nil {
+		return defaultIntn(n)
+	}
+	return m.intn(n)
+}
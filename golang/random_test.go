@@ -0,0 +1,152 @@
+// random_test.go 覆盖 variant 随机来源：带种子来源可复现、读取器来源均匀且传递错误，
+// 以及默认来源在并发编码下无锁竞争的基准。
+package idmix
+
+import (
+	"bytes"
+	crand "crypto/rand"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"testing"
+)
+
+// TestRandSourceReproducible 相同种子的两个实例产生相同的编码序列，不同种子不同。
+func TestRandSourceReproducible(t *testing.T) {
+	encodeAll := func(m *IdMix) []string {
+		out := make([]string, 64)
+		for i := range out {
+			out[i] = mustEncode(t, m, uint32(i))
+		}
+		return out
+	}
+	a, _ := New(WithRandSource(rand.NewPCG(1, 2)))
+	b, _ := New(WithRandSource(rand.NewPCG(1, 2)))
+	c, _ := New(WithRandSource(rand.NewPCG(3, 4)))
+	sa, sb, sc := encodeAll(a), encodeAll(b), encodeAll(c)
+	for i := range sa {
+		if sa[i] != sb[i] {
+			t.Fatalf("item %d: %q != %q", i, sa[i], sb[i])
+		}
+	}
+	same := 0
+	for i := range sa {
+		if sa[i] == sc[i] {
+			same++
+		}
+	}
+	if same == len(sa) {
+		t.Fatal("different seeds produced identical sequences")
+	}
+	t.Logf("seed (1,2): %v", sa[:4])
+
+	// EncodeTagged 与 Transcode 使用同一来源
+	idx, _ := NewIdx(WithTagBits(2))
+	ta, _ := New(WithIdx(idx), WithRandSource(rand.NewPCG(9, 9)))
+	tb, _ := New(WithIdx(idx), WithRandSource(rand.NewPCG(9, 9)))
+	for i := 0; i < 16; i++ {
+		x, _ := ta.EncodeTagged(i%4, uint8(1))
+		y, _ := tb.EncodeTagged(i%4, uint8(1))
+		if x != y {
+			t.Fatalf("EncodeTagged %d: %q != %q", i, x, y)
+		}
+		if mustTranscode(t, x, ta) != mustTranscode(t, y, tb) {
+			t.Fatalf("Transcode %d differs", i)
+		}
+	}
+	if _, err := New(WithRandSource(nil)); err == nil {
+		t.Fatal("expected nil source error")
+	}
+}
+
+func mustTranscode(t *testing.T, s string, m *IdMix) string {
+	t.Helper()
+	out, err := Transcode(s, m, m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+// TestRandReader 读取器来源覆盖全部 variant、拒绝采样保证均匀，读取错误由 Encode 返回。
+func TestRandReader(t *testing.T) {
+	// 字节 0..255 依次出现：maxVariants=24 时 [240,256) 被拒绝，其余每个 variant 恰好 10 次
+	seq := make([]byte, 256)
+	for i := range seq {
+		seq[i] = byte(i)
+	}
+	idx, _ := NewIdx(WithMaxVariants(24))
+	m, err := New(WithIdx(idx), WithRandReader(bytes.NewReader(seq)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	counts := make([]int, 24)
+	for i := 0; i < 240; i++ {
+		ins, err := m.Inspect(mustEncode(t, m, uint8(1)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		counts[ins.VariantID]++
+	}
+	for v, n := range counts {
+		if n != 10 {
+			t.Fatalf("variant %d chosen %d times, want 10: %v", v, n, counts)
+		}
+	}
+	// 剩余 16 字节全部被拒绝后读到 EOF
+	if _, err := m.Encode(uint8(1)); !errors.Is(err, io.EOF) {
+		t.Fatalf("expected reader error, got %v", err)
+	} else {
+		t.Logf("exhausted reader: %v", err)
+	}
+
+	cm, _ := New(WithRandReader(crand.Reader))
+	if _, err := cm.Decode(mustEncode(t, cm, uint32(1001))); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := New(WithRandReader(nil)); err == nil {
+		t.Fatal("expected nil reader error")
+	}
+}
+
+// BenchmarkEncodeParallel 多 goroutine 并发编码：默认来源无锁，ns/op 随 -cpu 增加而下降；
+// 带种子来源与 crypto/rand 读取器以互斥锁串行化，作为对照。
+func BenchmarkEncodeParallel(b *testing.B) {
+	def, _ := New()
+	seeded, _ := New(WithRandSource(rand.NewPCG(1, 2)))
+	reader, _ := New(WithRandReader(crand.Reader))
+	for _, c := range []struct {
+		name string
+		m    *IdMix
+	}{{"default", def}, {"source", seeded}, {"reader", reader}} {
+		b.Run(c.name, func(b *testing.B) {
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					if _, err := c.m.Encode(uint32(1001)); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		})
+	}
+}
+
+// BenchmarkVariantRand 只测选取 variant 的开销，排除编码本身，便于观察锁竞争。
+func BenchmarkVariantRand(b *testing.B) {
+	def, _ := New()
+	seeded, _ := New(WithRandSource(rand.NewPCG(1, 2)))
+	for _, c := range []struct {
+		name string
+		m    *IdMix
+	}{{"default", def}, {"source", seeded}} {
+		b.Run(c.name, func(b *testing.B) {
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					if _, err := c.m.randIntn(32); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		})
+	}
+}
@@ -0,0 +1,395 @@
+// reed_solomon.go 实现可纠错的文本层：每个字符对应 GF(2^m) 上的一个符号，
+// 在数据符号后追加 Reed–Solomon 校验符号，解码时可纠正最多 k 个错误字符。
+//
+// 字符表长度须为 2^m（m = 2~8，如 Crockford Base32 的 m = 5）。编码：
+//
+//	二进制 → 按 m 位大端切分为数据符号（末尾补 0 位）→ 追加 2k 个校验符号 → 逐符号映射字符
+//
+// 数据符号数 = ceil(8L/m)，解码时 L = floor(符号数·m/8)，无需长度前缀。
+// 码字（数据 + 校验）不超过 2^m − 1 个字符。
+//
+// RS 参数：本原多项式见 rsPrimitive，生成元 α = 2，首个连续根 α^0，
+// 生成多项式 g(x) = ∏_{i=0}^{2k−1} (x − α^i)，系统码（数据在前）。
+package idmix
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+)
+
+// ErrTooManyErrors 错误字符超过纠错能力。
+var ErrTooManyErrors = errors.New("too many errors to correct")
+
+// CorrectingCodec 为可报告纠错数量的 Codec。
+type CorrectingCodec interface {
+	Codec
+	// DecodeCorrected 解码 s，corrected 为被纠正的字符个数。
+	DecodeCorrected(s string) (data []byte, corrected int, err error)
+}
+
+// rsPrimitive 为 GF(2^m) 的本原多项式（含 x^m 项），下标为 m。
+var rsPrimitive = [9]int{2: 0x7, 3: 0xB, 4: 0x13, 5: 0x25, 6: 0x43, 7: 0x89, 8: 0x11D}
+
+// galoisField 为 GF(2^m) 的对数/反对数表。
+type galoisField struct {
+	order int // 乘法群阶 2^m − 1
+	exp   []int
+	log   []int
+}
+
+func newGaloisField(m int) *galoisField {
+	size := 1 << m
+	gf := &galoisField{order: size - 1, exp: make([]int, 2*size), log: make([]int, size)}
+	x := 1
+	for i := 0; i < gf.order; i++ {
+		gf.exp[i] = x
+		gf.log[x] = i
+		x <<= 1
+		if x&size != 0 {
+			x ^= rsPrimitive[m]
+		}
+	}
+	for i := gf.order; i < len(gf.exp); i++ {
+		gf.exp[i] = gf.exp[i-gf.order]
+	}
+	return gf
+}
+
+func (gf *galoisField) mul(x, y int) int {
+	if x == 0 || y == 0 {
+		return 0
+	}
+	return gf.exp[gf.log[x]+gf.log[y]]
+}
+
+func (gf *galoisField) div(x, y int) int {
+	if x == 0 {
+		return 0
+	}
+	return gf.exp[(gf.log[x]+gf.order-gf.log[y])%gf.order]
+}
+
+// pow 返回 x^p（x ≠ 0），p 可为负。
+func (gf *galoisField) pow(x, p int) int {
+	e := (gf.log[x] * p) % gf.order
+	if e < 0 {
+		e += gf.order
+	}
+	return gf.exp[e]
+}
+
+func (gf *galoisField) inverse(x int) int {
+	return gf.exp[gf.order-gf.log[x]]
+}
+
+// 多项式按最高次项在前存放。
+
+func (gf *galoisField) polyScale(p []int, x int) []int {
+	out := make([]int, len(p))
+	for i, c := range p {
+		out[i] = gf.mul(c, x)
+	}
+	return out
+}
+
+func polyAdd(p, q []int) []int {
+	n := max(len(p), len(q))
+	out := make([]int, n)
+	for i, c := range p {
+		out[i+n-len(p)] = c
+	}
+	for i, c := range q {
+		out[i+n-len(q)] ^= c
+	}
+	return out
+}
+
+func (gf *galoisField) polyMul(p, q []int) []int {
+	out := make([]int, len(p)+len(q)-1)
+	for j, b := range q {
+		for i, a := range p {
+			out[i+j] ^= gf.mul(a, b)
+		}
+	}
+	return out
+}
+
+func (gf *galoisField) polyEval(p []int, x int) int {
+	y := p[0]
+	for _, c := range p[1:] {
+		y = gf.mul(y, x) ^ c
+	}
+	return y
+}
+
+// polyMod 返回 dividend 除以首一多项式 divisor 的余式。
+func (gf *galoisField) polyMod(dividend, divisor []int) []int {
+	out := append([]int(nil), dividend...)
+	for i := 0; i < len(dividend)-(len(divisor)-1); i++ {
+		coef := out[i]
+		if coef == 0 {
+			continue
+		}
+		for j := 1; j < len(divisor); j++ {
+			out[i+j] ^= gf.mul(divisor[j], coef)
+		}
+	}
+	return out[len(out)-(len(divisor)-1):]
+}
+
+// RSCodec 为带 Reed–Solomon 纠错的文本层 Codec。
+type RSCodec struct {
+	chars  []rune
+	index  map[rune]int
+	m      int
+	gf     *galoisField
+	parity int   // 校验符号个数 2k
+	gen    []int // 生成多项式
+}
+
+// NewRSCodec 以 alphabet（长度 2^m，m = 2~8）创建可纠正 k 个错误字符的 Codec。
+func NewRSCodec(alphabet string, k int) (*RSCodec, error) {
+	chars := []rune(alphabet)
+	n := len(chars)
+	if n < 4 || n > 256 || n&(n-1) != 0 {
+		return nil, fmt.Errorf("alphabet size must be a power of two between 4 and 256, got %d", n)
+	}
+	m := bits.Len(uint(n)) - 1
+	gf := newGaloisField(m)
+	if k < 1 || 2*k >= gf.order {
+		return nil, fmt.Errorf("k must be between 1 and %d for a %d-character alphabet", (gf.order-1)/2, n)
+	}
+	c := &RSCodec{chars: chars, index: make(map[rune]int, n), m: m, gf: gf, parity: 2 * k}
+	for i, r := range chars {
+		if _, ok := c.index[r]; ok {
+			return nil, fmt.Errorf("alphabet contains duplicate character %q", r)
+		}
+		c.index[r] = i
+	}
+	if c.MaxDataBytes() < 1 {
+		return nil, fmt.Errorf("k=%d leaves no room for data in a %d-character alphabet", k, n)
+	}
+	c.gen = []int{1}
+	for i := 0; i < c.parity; i++ {
+		c.gen = gf.polyMul(c.gen, []int{1, gf.pow(2, i)})
+	}
+	return c, nil
+}
+
+// Correctable 返回可纠正的错误字符个数 k。
+func (c *RSCodec) Correctable() int { return c.parity / 2 }
+
+// MaxDataBytes 返回单次可编码的最大字节数（受码字长度 2^m − 1 限制）。
+func (c *RSCodec) MaxDataBytes() int {
+	return (c.gf.order - c.parity) * c.m / 8
+}
+
+// Encode 实现 Codec。
+func (c *RSCodec) Encode(data []byte) (string, error) {
+	if len(data) == 0 {
+		return "", errors.New("empty data")
+	}
+	if len(data) > c.MaxDataBytes() {
+		return "", fmt.Errorf("data too long: %d bytes (max %d)", len(data), c.MaxDataBytes())
+	}
+	msg := c.toSymbols(data)
+	out := append(msg, make([]int, c.parity)...)
+	copy(out[len(msg):], c.gf.polyMod(out, c.gen))
+	runes := make([]rune, len(out))
+	for i, s := range out {
+		runes[i] = c.chars[s]
+	}
+	return string(runes), nil
+}
+
+// Decode 实现 Codec。
+func (c *RSCodec) Decode(s string) ([]byte, error) {
+	data, _, err := c.DecodeCorrected(s)
+	return data, err
+}
+
+// DecodeCorrected 实现 CorrectingCodec。不在字符表中的字符按错误字符处理。
+func (c *RSCodec) DecodeCorrected(s string) ([]byte, int, error) {
+	runes := []rune(s)
+	if len(runes) <= c.parity {
+		return nil, 0, fmt.Errorf("code too short: %d characters (need more than %d)", len(runes), c.parity)
+	}
+	if len(runes) > c.gf.order {
+		return nil, 0, fmt.Errorf("code too long: %d characters (max %d)", len(runes), c.gf.order)
+	}
+	word := make([]int, len(runes))
+	for i, r := range runes {
+		word[i] = c.index[r] // 未知字符取 0，由纠错处理
+	}
+	corrected, err := c.correct(word)
+	if err != nil {
+		return nil, 0, err
+	}
+	data, err := c.fromSymbols(word[:len(word)-c.parity])
+	if err != nil {
+		return nil, 0, err
+	}
+	return data, corrected, nil
+}
+
+// toSymbols 将字节按 m 位大端切分，末尾不足 m 位时补 0。
+func (c *RSCodec) toSymbols(data []byte) []int {
+	n := (len(data)*8 + c.m - 1) / c.m
+	out := make([]int, 0, n)
+	acc, nbits := 0, 0
+	for _, b := range data {
+		acc = acc<<8 | int(b)
+		nbits += 8
+		for nbits >= c.m {
+			nbits -= c.m
+			out = append(out, (acc>>nbits)&(1<<c.m-1))
+		}
+	}
+	if nbits > 0 {
+		out = append(out, (acc<<(c.m-nbits))&(1<<c.m-1))
+	}
+	return out
+}
+
+// fromSymbols 为 toSymbols 的逆操作，要求补位为 0 且符号数与字节数一致。
+func (c *RSCodec) fromSymbols(symbols []int) ([]byte, error) {
+	n := len(symbols) * c.m / 8
+	if (n*8+c.m-1)/c.m != len(symbols) {
+		return nil, errors.New("invalid data symbol count")
+	}
+	out := make([]byte, 0, n)
+	acc, nbits := 0, 0
+	for _, s := range symbols {
+		acc = acc<<c.m | s
+		nbits += c.m
+		if nbits >= 8 {
+			nbits -= 8
+			out = append(out, byte(acc>>nbits))
+			acc &= 1<<nbits - 1
+		}
+	}
+	if acc != 0 {
+		return nil, errors.New("non-zero padding bits")
+	}
+	return out, nil
+}
+
+func (c *RSCodec) syndromes(word []int) ([]int, bool) {
+	// 前置 0 便于 Berlekamp–Massey 下标计算
+	synd := make([]int, c.parity+1)
+	clean := true
+	for i := 0; i < c.parity; i++ {
+		synd[i+1] = c.gf.polyEval(word, c.gf.pow(2, i))
+		clean = clean && synd[i+1] == 0
+	}
+	return synd, clean
+}
+
+// correct 原地纠正 word，返回纠正的符号个数。
+func (c *RSCodec) correct(word []int) (int, error) {
+	gf := c.gf
+	synd, clean := c.syndromes(word)
+	if clean {
+		return 0, nil
+	}
+
+	// Berlekamp–Massey 求错误位置多项式
+	errLoc, oldLoc := []int{1}, []int{1}
+	for i := 0; i < c.parity; i++ {
+		k := i + 1
+		delta := synd[k]
+		for j := 1; j < len(errLoc); j++ {
+			delta ^= gf.mul(errLoc[len(errLoc)-1-j], synd[k-j])
+		}
+		oldLoc = append(oldLoc, 0)
+		if delta != 0 {
+			if len(oldLoc) > len(errLoc) {
+				newLoc := gf.polyScale(oldLoc, delta)
+				oldLoc = gf.polyScale(errLoc, gf.inverse(delta))
+				errLoc = newLoc
+			}
+			errLoc = polyAdd(errLoc, gf.polyScale(oldLoc, delta))
+		}
+	}
+	for len(errLoc) > 0 && errLoc[0] == 0 {
+		errLoc = errLoc[1:]
+	}
+	nerr := len(errLoc) - 1
+	if nerr*2 > c.parity {
+		return 0, ErrTooManyErrors
+	}
+
+	// Chien 搜索求错误位置
+	rev := make([]int, len(errLoc))
+	for i, v := range errLoc {
+		rev[len(errLoc)-1-i] = v
+	}
+	var pos []int
+	for i := 0; i < len(word); i++ {
+		if gf.polyEval(rev, gf.pow(2, i)) == 0 {
+			pos = append(pos, len(word)-1-i)
+		}
+	}
+	if len(pos) != nerr {
+		return 0, ErrTooManyErrors
+	}
+
+	// Forney 算法求错误值
+	coefPos := make([]int, len(pos))
+	loc := []int{1}
+	for i, p := range pos {
+		coefPos[i] = len(word) - 1 - p
+		loc = gf.polyMul(loc, polyAdd([]int{1}, []int{gf.pow(2, coefPos[i]), 0}))
+	}
+	syndRev := make([]int, len(synd))
+	for i, v := range synd {
+		syndRev[len(synd)-1-i] = v
+	}
+	divisor := make([]int, len(loc)+1)
+	divisor[0] = 1
+	eval := gf.polyMod(gf.polyMul(syndRev, loc), divisor)
+
+	xs := make([]int, len(coefPos))
+	for i, cp := range coefPos {
+		xs[i] = gf.pow(2, cp)
+	}
+	for i, xi := range xs {
+		xiInv := gf.inverse(xi)
+		prime := 1
+		for j, xj := range xs {
+			if j != i {
+				prime = gf.mul(prime, 1^gf.mul(xiInv, xj))
+			}
+		}
+		if prime == 0 {
+			return 0, ErrTooManyErrors
+		}
+		y := gf.mul(xi, gf.polyEval(eval, xiInv))
+		word[pos[i]] ^= gf.div(y, prime)
+	}
+	if _, ok := c.syndromes(word); !ok {
+		return 0, ErrTooManyErrors
+	}
+	return nerr, nil
+}
+
+// DecodeCorrected 解码并返回被纠正的字符个数；Codec 须实现 CorrectingCodec。
+// 上限与严格模式同 Decode：严格模式下输入须为规范写法，有字符被纠正时返回 ErrNonCanonical。
+func (m *IdMix) DecodeCorrected(s string) ([]any, int, error) {
+	cc, ok := m.codec.(CorrectingCodec)
+	if !ok {
+		return nil, 0, fmt.Errorf("codec %T does not support error correction", m.codec)
+	}
+	corrected := 0
+	_, values, err := m.decodeChecked(OpDecodeCorrected, s, func(s string) ([]byte, error) {
+		return m.checkedText(s, func(s string) (data []byte, err error) {
+			data, corrected, err = cc.DecodeCorrected(s)
+			return data, err
+		})
+	}, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	return values, corrected, nil
+}
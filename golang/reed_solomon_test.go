@@ -0,0 +1,154 @@
+// reed_solomon_test.go 覆盖 RSCodec：符号打包、随机错误纠正、超出纠错能力的检出。
+package idmix
+
+import (
+	"bytes"
+	"errors"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestRSCodecRoundTrip(t *testing.T) {
+	cases := []struct {
+		alphabet string
+		k        int
+	}{
+		{AlphabetCrockford32, 1},
+		{AlphabetCrockford32, 3},
+		{AlphabetURLSafe64, 4},
+		{"01234567", 2},
+		{"0123456789abcdef", 2},
+	}
+	r := rand.New(rand.NewSource(5))
+	for _, c := range cases {
+		rs, err := NewRSCodec(c.alphabet, c.k)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for n := 1; n <= rs.MaxDataBytes(); n++ {
+			data := make([]byte, n)
+			r.Read(data)
+			s, err := rs.Encode(data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, corrected, err := rs.DecodeCorrected(s)
+			if err != nil || corrected != 0 || !bytes.Equal(got, data) {
+				t.Fatalf("m=%d k=%d n=%d: %x %d %v", rs.m, c.k, n, got, corrected, err)
+			}
+		}
+		t.Logf("alphabet %d chars, k=%d: max %d data bytes", len([]rune(c.alphabet)), c.k, rs.MaxDataBytes())
+		if _, err := rs.Encode(make([]byte, rs.MaxDataBytes()+1)); err == nil {
+			t.Fatal("expected data too long error")
+		}
+	}
+}
+
+// TestRSCodecCorrects 随机破坏 1~k 个字符均被纠正，纠正数与破坏数一致。
+func TestRSCodecCorrects(t *testing.T) {
+	r := rand.New(rand.NewSource(6))
+	for _, k := range []int{1, 2, 3, 4} {
+		rs, err := NewRSCodec(AlphabetCrockford32, k)
+		if err != nil {
+			t.Fatal(err)
+		}
+		alphabet := []rune(AlphabetCrockford32)
+		for i := 0; i < 2000; i++ {
+			data := make([]byte, 1+r.Intn(rs.MaxDataBytes()))
+			r.Read(data)
+			s, _ := rs.Encode(data)
+			runes := []rune(s)
+			nerr := 1 + r.Intn(k)
+			for _, pos := range r.Perm(len(runes))[:nerr] {
+				orig := runes[pos]
+				for runes[pos] == orig {
+					runes[pos] = alphabet[r.Intn(len(alphabet))]
+				}
+			}
+			got, corrected, err := rs.DecodeCorrected(string(runes))
+			if err != nil || corrected != nerr || !bytes.Equal(got, data) {
+				t.Fatalf("k=%d %q→%q: %x corrected=%d/%d %v", k, s, string(runes), got, corrected, nerr, err)
+			}
+		}
+	}
+}
+
+// TestRSCodecBeyondCapacity 超出纠错能力时大多被拒绝，统计误纠为其他数据的比例。
+func TestRSCodecBeyondCapacity(t *testing.T) {
+	r := rand.New(rand.NewSource(8))
+	rs, _ := NewRSCodec(AlphabetCrockford32, 2)
+	alphabet := []rune(AlphabetCrockford32)
+	const n = 5000
+	rejected, miscorrected := 0, 0
+	for i := 0; i < n; i++ {
+		data := make([]byte, 6)
+		r.Read(data)
+		s, _ := rs.Encode(data)
+		runes := []rune(s)
+		for _, pos := range r.Perm(len(runes))[:3] {
+			orig := runes[pos]
+			for runes[pos] == orig {
+				runes[pos] = alphabet[r.Intn(len(alphabet))]
+			}
+		}
+		got, _, err := rs.DecodeCorrected(string(runes))
+		switch {
+		case err != nil:
+			rejected++
+		case !bytes.Equal(got, data):
+			miscorrected++
+		}
+	}
+	t.Logf("k=2, 3 errors: rejected %d/%d, miscorrected %d/%d", rejected, n, miscorrected, n)
+	if rejected < n*3/4 {
+		t.Fatalf("too few rejections: %d", rejected)
+	}
+}
+
+func TestRSCodecWithIdMix(t *testing.T) {
+	rs, err := NewRSCodec(AlphabetCrockford32, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := New(WithCodec(rs))
+	if err != nil {
+		t.Fatal(err)
+	}
+	vals := []any{uint32(1001), uint16(42)}
+	s, err := m.EncodeWithVariant(3, vals...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	typo := []rune(s)
+	typo[2], typo[9] = '?', 'Z'
+	list, corrected, err := m.DecodeCorrected(string(typo))
+	if err != nil || corrected != 2 || !reflect.DeepEqual(list, vals) {
+		t.Fatalf("%q: %v %d %v", string(typo), list, corrected, err)
+	}
+	t.Logf("%v => %s, read as %s => %v (%d corrected)", vals, s, string(typo), list, corrected)
+
+	plain, _ := New()
+	if _, _, err := plain.DecodeCorrected("abc"); err == nil {
+		t.Fatal("expected unsupported codec error")
+	}
+	if _, err := NewRSCodec(DefaultAlphabet, 2); err == nil {
+		t.Fatal("62 characters is not a power of two")
+	}
+	if _, err := NewRSCodec(AlphabetCrockford32, 16); err == nil {
+		t.Fatal("expected k range error")
+	}
+	if _, err := NewRSCodec("0120", 1); err == nil {
+		t.Fatal("expected duplicate character error")
+	}
+	if _, err := NewRSCodec("0123", 1); err == nil {
+		t.Fatal("expected no room for data error")
+	}
+	if _, _, err := rs.DecodeCorrected("ABCD"); err == nil {
+		t.Fatal("expected code too short error")
+	}
+	// 与任何码字相距都超过 k 的串
+	if _, _, err := rs.DecodeCorrected("0000000000ZZZZZZ"); !errors.Is(err, ErrTooManyErrors) {
+		t.Fatalf("got %v", err)
+	}
+}
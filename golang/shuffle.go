@@ -0,0 +1,62 @@
+// shuffle.go 实现由密钥种子确定性打乱字符表（跨语言算法见 arithmetic.md 5.1 节）。
+//
+// 字符表顺序即 RadixCodec 的轻量密钥：同一 base + seed 在任何语言实现中
+// 都得到相同的字符表，配置中只需保存种子引用而非手工打乱的字符串。
+package idmix
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+)
+
+// shuffleDomain 为随机流的域分隔前缀，避免与种子的其他用途产生相同摘要。
+const shuffleDomain = "idmix/alphabet/v1"
+
+// shuffleStream 输出 SHA-256(domain || seed || be32(k)) 依次拼接的字节流。
+type shuffleStream struct {
+	seed    []byte
+	counter uint32
+	buf     []byte
+}
+
+func (s *shuffleStream) uint32() uint32 {
+	if len(s.buf) < 4 {
+		h := sha256.New()
+		h.Write([]byte(shuffleDomain))
+		h.Write(s.seed)
+		var ctr [4]byte
+		binary.BigEndian.PutUint32(ctr[:], s.counter)
+		h.Write(ctr[:])
+		s.counter++
+		s.buf = h.Sum(nil)
+	}
+	v := binary.BigEndian.Uint32(s.buf[:4])
+	s.buf = s.buf[4:]
+	return v
+}
+
+// uniform 以拒绝采样返回 [0, bound) 内无偏的整数。
+func (s *shuffleStream) uniform(bound uint32) uint32 {
+	limit := uint64(1<<32) - uint64(1<<32)%uint64(bound)
+	for {
+		r := s.uint32()
+		if uint64(r) < limit {
+			return r % bound
+		}
+	}
+}
+
+// ShuffleAlphabet 以 seed 确定性打乱 base 的字符顺序（按 rune 处理）。
+//
+// 算法为 Fisher–Yates：i 从 n-1 递减到 1，j = uniform(i+1)，交换 i 与 j；
+// 随机数取自 SHA-256 计数器流的 4 字节大端整数，超出 2^32 - 2^32 mod (i+1) 的值丢弃重抽。
+// 不同 seed 得到不同顺序；字符集合与 base 相同，重复字符仍由 NewRadixCodec 拒绝。
+func ShuffleAlphabet(base string, seed []byte) string {
+	runes := []rune(base)
+	s := &shuffleStream{seed: seed}
+	for i := len(runes) - 1; i > 0; i-- {
+		j := s.uniform(uint32(i + 1))
+		runes[i], runes[j] = runes[j], runes[i]
+	}
+	return string(runes)
+}
@@ -0,0 +1,99 @@
+// shuffle_test.go 固定 ShuffleAlphabet 的跨语言输出，并覆盖 WithSecretAlphabet 与预置字符表。
+package idmix
+
+import (
+	"sort"
+	"testing"
+)
+
+// shuffleVectors 为跨语言实现应复现的固定输出。
+var shuffleVectors = []struct {
+	base string
+	seed string
+	want string
+}{
+	{DefaultAlphabet, "secret", "XJOabf32sceuWV6Ky0AIRjPqLUC15NYrlvHxiZMSFg8Btwo9zk4pQGTh7EmndD"},
+	{DefaultAlphabet, "another secret", "fs8vGcl5XQ2C3aZHx1hPeTMj4igVn7KDOFy9IobUNpwuRdLJrAz6tYBq0kWmES"},
+	{AlphabetCrockford32, "secret", "BVFWSRNCP936J7T2GHA01KQ8YX4EMDZ5"},
+	{"abcd", "k", "bcda"},
+	{"一二三四五六七八九十", "secret", "十六七四三一五二九八"},
+}
+
+func TestShuffleAlphabetVectors(t *testing.T) {
+	for _, v := range shuffleVectors {
+		got := ShuffleAlphabet(v.base, []byte(v.seed))
+		t.Logf("ShuffleAlphabet(%q, %q) = %q", v.base, v.seed, got)
+		if got != v.want {
+			t.Fatalf("got %q, want %q", got, v.want)
+		}
+		if sortedRunes(got) != sortedRunes(v.base) {
+			t.Fatalf("shuffle changed the character set: %q", got)
+		}
+	}
+	if ShuffleAlphabet(DefaultAlphabet, []byte("a")) == ShuffleAlphabet(DefaultAlphabet, []byte("b")) {
+		t.Fatal("different seeds should give different orders")
+	}
+}
+
+func sortedRunes(s string) string {
+	r := []rune(s)
+	sort.Slice(r, func(i, j int) bool { return r[i] < r[j] })
+	return string(r)
+}
+
+func TestWithSecretAlphabet(t *testing.T) {
+	seed := []byte("secret")
+	m, err := New(WithAlphabet(AlphabetCrockford32), WithSecretAlphabet(seed))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rc := m.Codec().(*RadixCodec)
+	if rc.Alphabet() != ShuffleAlphabet(AlphabetCrockford32, seed) {
+		t.Fatalf("alphabet %q", rc.Alphabet())
+	}
+	logRoundTrip(t, m, "Crockford32 + 密钥打乱", uint32(1001), "eu")
+
+	def, err := New(WithSecretAlphabet(seed))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if def.Codec().(*RadixCodec).Alphabet() != ShuffleAlphabet(DefaultAlphabet, seed) {
+		t.Fatal("default alphabet should be shuffled")
+	}
+
+	if _, err := New(WithSecretAlphabet(nil)); err == nil {
+		t.Fatal("expected empty seed error")
+	}
+	if _, err := New(WithCodec(NewBase64Codec()), WithSecretAlphabet(seed)); err == nil {
+		t.Fatal("expected non-radix codec error")
+	}
+}
+
+func TestAlphabetPresets(t *testing.T) {
+	presets := []struct {
+		name     string
+		alphabet string
+		base     int
+	}{
+		{"url_safe_64", AlphabetURLSafe64, 64},
+		{"crockford_32", AlphabetCrockford32, 32},
+		{"lower_36", AlphabetLower36, 36},
+		{"no_lookalike_55", AlphabetNoLookalike55, 55},
+	}
+	for _, p := range presets {
+		t.Run(p.name, func(t *testing.T) {
+			rc, err := NewRadixCodec(p.alphabet)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if rc.Base() != p.base {
+				t.Fatalf("base %d, want %d", rc.Base(), p.base)
+			}
+			m, err := New(WithCodec(rc))
+			if err != nil {
+				t.Fatal(err)
+			}
+			logRoundTrip(t, m, p.name, uint16(5), int64(-1), uint32(40))
+		})
+	}
+}
@@ -0,0 +1,81 @@
+// strict.go 实现严格（规范形式）解码：只接受重新编码后与输入逐字节相同的编码串，
+// 使编码串可直接用作缓存键或数据库唯一列。
+//
+// 默认解码是宽容的，以下非规范形式都能解出相同的值：
+//
+//	文本层：RadixCodec 标准模式的前导零字符；其他 Codec 的等价写法（如 Base64 末字符未用位、Crockford 小写）
+//	对象：可内嵌的小整数使用扩展形式；数值使用比最小宽度更宽的 payload
+//	头部：校验方式或版本标记与本实例编码时不同
+package idmix
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"unicode/utf8"
+)
+
+// ErrNonCanonical 编码串能解码，但不是本配置编码产生的规范形式（可用 errors.Is 判断）。
+var ErrNonCanonical = errors.New("non-canonical encoding")
+
+// WithStrictDecoding 使 Idx 拒绝非规范的二进制块：对象须为最短形式，
+// 校验方式与版本标记须与本 Idx 编码时一致。
+func WithStrictDecoding() IdxOption {
+	return func(idx *Idx) error {
+		idx.strict = true
+		return nil
+	}
+}
+
+// Strict 报告是否为严格解码。
+func (idx *Idx) Strict() bool { return idx.strict }
+
+// WithStrict 启用严格解码：IDX 层按 WithStrictDecoding 检查，
+// 文本层要求 Codec 重新编码还原出的二进制后与输入完全相同。
+func WithStrict() Option {
+	return func(m *IdMix) error {
+		m.strict = true
+		return nil
+	}
+}
+
+// checkCanonicalHeader 检查头部形式是否与本 Idx 编码时一致。
+func (idx *Idx) checkCanonicalHeader(h idxHeader) error {
+	if h.mode != idx.checksumMode {
+		return fmt.Errorf("%w: checksum %s, this idx encodes %s", ErrNonCanonical, h.mode, idx.checksumMode)
+	}
+	if h.version != idx.version {
+		return fmt.Errorf("%w: version marker %s, this idx encodes %s", ErrNonCanonical, h.version, idx.version)
+	}
+	return nil
+}
+
+// checkCanonicalObject 检查 raw（对象的原始字节）是否为 obj 的最短编码。
+func checkCanonicalObject(obj dataObject, raw []byte) error {
+	want, err := encodeObject(obj)
+	if err != nil || bytes.Equal(want, raw) {
+		return err
+	}
+	if len(want) == 1 {
+		return fmt.Errorf("%w: value %s fits the embedded form", ErrNonCanonical, formatCrossLangVal(obj.otype, obj.val))
+	}
+	return fmt.Errorf("%w: value %s uses a %d-byte payload, shortest is %d",
+		ErrNonCanonical, formatCrossLangVal(obj.otype, obj.val), len(raw)-1, len(want)-1)
+}
+
+// checkCanonicalText 检查 s 是否为 data 经 Codec 编码的规范写法。
+func (m *IdMix) checkCanonicalText(s string, data []byte) error {
+	if rc, ok := m.codec.(*RadixCodec); ok && rc.mode == radixStandard {
+		if r, n := utf8.DecodeRuneInString(s); n < len(s) && r == rc.chars[0] {
+			return fmt.Errorf("%w: leading zero character %q", ErrNonCanonical, r)
+		}
+	}
+	re, err := m.codec.Encode(data)
+	if err != nil {
+		return err
+	}
+	if re != s {
+		return fmt.Errorf("%w: %T re-encodes the input as %q", ErrNonCanonical, m.codec, re)
+	}
+	return nil
+}
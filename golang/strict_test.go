@@ -0,0 +1,232 @@
+// strict_test.go 覆盖严格解码：逐一构造各类非规范形式，确认严格模式拒绝、默认模式仍然接受，
+// 以及规范编码串在严格模式下照常解码。
+package idmix
+
+import (
+	"encoding/binary"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// rawBlock 按 idx 的掩码与校验规则组装块（header 的 check 位留空，由本函数填写）。
+func rawBlock(idx *Idx, variantID int, header, objBytes []byte) []byte {
+	obj := append([]byte(nil), objBytes...)
+	idx.maskObjects(obj, variantID)
+	data := append(append([]byte(nil), header...), obj...)
+	data[0] |= idx.checksum(data)
+	return data
+}
+
+func mustIdx(t *testing.T, opts ...IdxOption) *Idx {
+	t.Helper()
+	idx, err := NewIdx(opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return idx
+}
+
+// TestStrictNonCanonicalObjects 对象层的非规范形式：可内嵌的值写成扩展形式、payload 比最短宽度更宽。
+func TestStrictNonCanonicalObjects(t *testing.T) {
+	lenient, _ := NewIdx()
+	strict, err := NewIdx(WithStrictDecoding())
+	if err != nil || !strict.Strict() || lenient.Strict() {
+		t.Fatal(err)
+	}
+	neg := int64(-300)
+	int32Payload := binary.LittleEndian.AppendUint64(nil, uint64(neg))
+	cases := []struct {
+		name string
+		obj  []byte
+		want any
+	}{
+		{"uint8_extended", []byte{0x80, 0x05}, uint8(5)},
+		{"uint32_4byte_payload", []byte{0xA2, 0xE8, 0x03, 0x00, 0x00}, uint32(1000)},
+		{"int32_8byte_payload", append([]byte{0xB6}, int32Payload...), int32(-300)},
+		{"uint64_8byte_payload", []byte{0xB3, 0xFF, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}, uint64(255)},
+	}
+	for _, c := range cases {
+		for v := 0; v < strict.MaxVariants(); v++ {
+			data := rawBlock(strict, v, []byte{byte(v << strict.CheckBits())}, c.obj)
+			values, err := lenient.Decode(data)
+			if err != nil || values[0] != c.want {
+				t.Fatalf("%s: lenient decode %v %v", c.name, values, err)
+			}
+			_, err = strict.Decode(data)
+			if !errors.Is(err, ErrNonCanonical) {
+				t.Fatalf("%s variant %d: %v", c.name, v, err)
+			}
+			if v == 0 {
+				t.Logf("%-22s %x => %v", c.name, data, err)
+			}
+		}
+	}
+
+	// 多对象块中只有第二个对象非规范，错误指明其位置
+	data := rawBlock(strict, 1, []byte{0x80 | 1<<strict.CheckBits(), 2}, []byte{0x05, 0x80, 0x07})
+	if _, err := lenient.Decode(data); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := strict.Decode(data); !errors.Is(err, ErrNonCanonical) || !strings.Contains(err.Error(), "object[1]") {
+		t.Fatalf("multi-object: %v", err)
+	}
+}
+
+// TestStrictNonCanonicalHeader 头部形式与本配置不同：多余的版本标记、CRC，或缺少应有的标记。
+func TestStrictNonCanonicalHeader(t *testing.T) {
+	cases := []struct {
+		name    string
+		encoder []IdxOption
+		decoder []IdxOption
+	}{
+		{"unexpected_version", []IdxOption{WithVersion(IdxV12)}, nil},
+		{"unexpected_crc8", []IdxOption{WithChecksum(ChecksumCRC8)}, nil},
+		{"crc16_for_crc8", []IdxOption{WithChecksum(ChecksumCRC16)}, []IdxOption{WithChecksum(ChecksumCRC8)}},
+		{"missing_version", nil, []IdxOption{WithVersion(IdxV12)}},
+		{"missing_version_crc8", []IdxOption{WithChecksum(ChecksumCRC8)}, []IdxOption{WithVersion(IdxV12), WithChecksum(ChecksumCRC8)}},
+	}
+	for _, c := range cases {
+		enc, err := NewIdx(c.encoder...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		lenient, _ := NewIdx(c.decoder...)
+		strict, _ := NewIdx(append(c.decoder, WithStrictDecoding())...)
+		data, _ := enc.Encode(uint32(1001), "eu")
+		if _, err := lenient.Decode(data); err != nil {
+			t.Fatalf("%s: lenient decode: %v", c.name, err)
+		}
+		_, err = strict.Decode(data)
+		if !errors.Is(err, ErrNonCanonical) {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		t.Logf("%-20s %x => %v", c.name, data, err)
+	}
+}
+
+// TestStrictNonCanonicalText 文本层的非规范形式：前导零字符、Base64 末字符未用位、Crockford 小写与连字符。
+func TestStrictNonCanonicalText(t *testing.T) {
+	radix := mustRadix(t, AlphabetURLSafe64)
+	cases := []struct {
+		name  string
+		codec Codec
+		alter func(s string) string
+	}{
+		{"radix_leading_zero", radix, func(s string) string { return string(radix.chars[0]) + s }},
+		{"radix_two_leading_zeros", radix, func(s string) string { return strings.Repeat(string(radix.chars[0]), 2) + s }},
+		{"base64url_trailing_bits", NewBase64URLCodec(), func(s string) string {
+			// 2 字节块编码为 3 字符，末字符低 2 位未使用
+			i := strings.IndexByte(AlphabetURLSafe64, s[len(s)-1])
+			return s[:len(s)-1] + string(AlphabetURLSafe64[i^1])
+		}},
+		{"crockford_lowercase", NewCrockfordCodec(), func(s string) string { return strings.ToLower(s) }},
+		{"crockford_hyphen", NewCrockfordCodec(), func(s string) string { return s[:1] + "-" + s[1:] }},
+		{"crockford_confusable", NewCrockfordCodec(), func(s string) string {
+			return strings.NewReplacer("0", "O", "1", "I").Replace(s)
+		}},
+	}
+	for _, c := range cases {
+		lenient, err := New(WithCodec(c.codec))
+		if err != nil {
+			t.Fatal(err)
+		}
+		strict, _ := New(WithCodec(c.codec), WithStrict())
+		tested := 0
+		for i := 0; i < 64; i++ {
+			s := mustEncode(t, lenient, uint8(10))
+			bad := c.alter(s)
+			if bad == s {
+				continue // 该编码串恰好没有可替换的字符
+			}
+			values, err := lenient.Decode(bad)
+			if err != nil || values[0] != uint8(10) {
+				t.Fatalf("%s: lenient decode %q: %v %v", c.name, bad, values, err)
+			}
+			if _, err := strict.Decode(s); err != nil {
+				t.Fatalf("%s: canonical %q rejected: %v", c.name, s, err)
+			}
+			_, err = strict.Decode(bad)
+			if !errors.Is(err, ErrNonCanonical) {
+				t.Fatalf("%s: %q accepted: %v", c.name, bad, err)
+			}
+			if tested++; tested == 1 {
+				t.Logf("%-24s %q -> %q => %v", c.name, s, bad, err)
+			}
+		}
+		if tested == 0 {
+			t.Fatalf("%s: no encoding could be altered", c.name)
+		}
+	}
+}
+
+// TestStrictCanonicalRoundTrip 严格模式下，各 Codec 与头部配置编码出的串都能解码，且各解码入口一致。
+func TestStrictCanonicalRoundTrip(t *testing.T) {
+	values := []any{uint8(0), uint8(200), int8(-1), uint16(300), int32(-70000), uint64(1 << 63), int64(-1 << 40), "eu"}
+	codecs := []Codec{
+		mustRadix(t, AlphabetURLSafe64),
+		NewBase64Codec(),
+		NewBase64URLCodec(),
+		NewBase32Codec(),
+		NewCrockfordCodec(),
+		NewBase58Codec(),
+		NewZ85Codec(),
+	}
+	for _, idxOpts := range [][]IdxOption{
+		nil,
+		{WithVersion(IdxV12)},
+		{WithChecksum(ChecksumCRC16), WithMaxObjects(16)},
+	} {
+		idx, err := NewIdx(idxOpts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, codec := range codecs {
+			m, err := New(WithIdx(idx), WithCodec(codec), WithStrict())
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i := range values {
+				s := mustEncode(t, m, values[:i+1]...)
+				got, err := m.Decode(s)
+				if err != nil || len(got) != i+1 {
+					t.Fatalf("%T %q: %v %v", codec, s, got, err)
+				}
+				if _, err := m.Inspect(s); err != nil {
+					t.Fatalf("%T inspect %q: %v", codec, s, err)
+				}
+			}
+		}
+	}
+
+	m, _ := New(WithStrict())
+	if !m.Idx().Strict() {
+		t.Fatal("WithStrict did not derive a strict Idx")
+	}
+	tagged, _ := New(WithIdx(mustIdx(t, WithMaxVariants(8), WithTagBits(2))), WithStrict())
+	s, err := tagged.EncodeTagged(3, uint32(7))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tag, _, err := tagged.DecodeTagged(s); err != nil || tag != 3 {
+		t.Fatalf("DecodeTagged: %d %v", tag, err)
+	}
+	if _, _, err := tagged.DecodeTagged(string(tagged.codec.(*RadixCodec).chars[0]) + s); !errors.Is(err, ErrNonCanonical) {
+		t.Fatalf("DecodeTagged leading zero: %v", err)
+	}
+}
+
+// TestStrictProfile Profile 的 strict 字段启用严格解码。
+func TestStrictProfile(t *testing.T) {
+	m, err := NewFromProfile(Profile{Strict: true}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := mustEncode(t, m, uint32(1001))
+	if _, err := m.Decode(s); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Decode(string(m.codec.(*RadixCodec).chars[0]) + s); !errors.Is(err, ErrNonCanonical) {
+		t.Fatalf("leading zero: %v", err)
+	}
+}
@@ -0,0 +1,88 @@
+// tag.go 将 header 的 variant_id 拆分为调用方可见的 tag 与随机部分，
+// 在不增加对象的前提下携带小型判别值（记录类型、环境、分片等）。
+//
+// 设 tagBits = t、maxVariants = V，随机部分跨度 span = V / 2^t：
+//
+//	variant_id = tag * span + random,  tag ∈ [0, 2^t), random ∈ [0, span)
+//
+// 掩码仍由完整 variant_id 派生；tag 位于明文 header，不提供保密性。
+package idmix
+
+import (
+	"errors"
+	"fmt"
+)
+
+// WithTagBits 设置 variant_id 中 tag 所占的位数（默认 0，有效 0~5）。
+// maxVariants 须能被 2^n 整除，tag 取值范围为 [0, 2^n)。
+func WithTagBits(n int) IdxOption {
+	return func(idx *Idx) error {
+		if n < 0 || n > 5 {
+			return errors.New("tagBits must be between 0 and 5")
+		}
+		idx.tagBits = n
+		return nil
+	}
+}
+
+// validateTagSplit 检查 variant 空间能否均分为 2^tagBits 个 tag。
+func validateTagSplit(maxVariants, tagBits int) error {
+	if maxVariants%(1<<tagBits) != 0 {
+		return fmt.Errorf("maxVariants %d is not divisible by 2^tagBits (%d)", maxVariants, 1<<tagBits)
+	}
+	return nil
+}
+
+// TagBits 返回 variant_id 中 tag 所占的位数。
+func (idx *Idx) TagBits() int { return idx.tagBits }
+
+// MaxTag 返回允许的最大 tag 值（2^tagBits - 1）。
+func (idx *Idx) MaxTag() int { return 1<<idx.tagBits - 1 }
+
+// tagSpan 返回每个 tag 对应的随机 variant 个数。
+func (idx *Idx) tagSpan() int { return idx.maxVariants >> idx.tagBits }
+
+// taggedVariant 以 intn 为 tag 随机选取一个 variant_id。
+func (idx *Idx) taggedVariant(tag int, intn intnFunc) (int, error) {
+	if tag < 0 || tag > idx.MaxTag() {
+		return 0, fmt.Errorf("invalid tag %d (max %d)", tag, idx.MaxTag())
+	}
+	r, err := intn(idx.tagSpan())
+	if err != nil {
+		return 0, err
+	}
+	return tag*idx.tagSpan() + r, nil
+}
+
+// TagOf 读取二进制块 header 中的 tag（不做校验和与对象解析）。
+func (idx *Idx) TagOf(data []byte) (int, error) {
+	variantID, err := idx.VariantOf(data)
+	if err != nil {
+		return 0, err
+	}
+	return variantID / idx.tagSpan(), nil
+}
+
+// EncodeTagged 编码 values，并把 tag 写入 variant_id 的高位（其余位随机）。
+func (m *IdMix) EncodeTagged(tag int, values ...any) (string, error) {
+	if len(values) < 1 {
+		return m.encodeRejected(errors.New("at least one value is required"))
+	}
+	variantID, err := m.idx.taggedVariant(tag, m.randIntn)
+	if err != nil {
+		return m.encodeRejected(err)
+	}
+	return m.EncodeWithVariant(variantID, values...)
+}
+
+// DecodeTagged 解码 EncodeTagged 的输出，返回 tag 与值。
+func (m *IdMix) DecodeTagged(s string) (tag int, values []any, err error) {
+	data, values, err := m.decodeObserved(nil, OpDecodeTagged, s)
+	if err != nil {
+		return 0, nil, err
+	}
+	if tag, err = m.idx.TagOf(data); err != nil {
+		return 0, nil, err
+	}
+	return tag, values, nil
+}
@@ -0,0 +1,97 @@
+// tag_test.go 覆盖 WithTagBits 拆分 variant 空间与 EncodeTagged / DecodeTagged。
+package idmix
+
+import (
+	"testing"
+)
+
+func TestEncodeTagged(t *testing.T) {
+	cases := []struct {
+		tagBits, maxVariants int
+	}{
+		{1, 32}, {2, 32}, {3, 16}, {5, 32}, {2, 4},
+	}
+	for _, c := range cases {
+		idx, err := NewIdx(WithTagBits(c.tagBits), WithMaxVariants(c.maxVariants))
+		if err != nil {
+			t.Fatal(err)
+		}
+		m, err := New(WithIdx(idx))
+		if err != nil {
+			t.Fatal(err)
+		}
+		span := c.maxVariants >> c.tagBits
+		for tag := 0; tag <= idx.MaxTag(); tag++ {
+			variants := map[int]bool{}
+			for i := 0; i < 200; i++ {
+				s, err := m.EncodeTagged(tag, uint32(1001), "eu")
+				if err != nil {
+					t.Fatal(err)
+				}
+				got, list, err := m.DecodeTagged(s)
+				if err != nil || got != tag || list[0].(uint32) != 1001 || list[1].(string) != "eu" {
+					t.Fatalf("tagBits=%d tag=%d: %d %v %v", c.tagBits, tag, got, list, err)
+				}
+				info, _ := m.Inspect(s)
+				variants[info.VariantID] = true
+			}
+			// 随机部分覆盖该 tag 的全部 variant
+			if len(variants) != span {
+				t.Fatalf("tagBits=%d tag=%d: %d distinct variants, want %d", c.tagBits, tag, len(variants), span)
+			}
+		}
+		t.Logf("tagBits=%d maxVariants=%d: tags 0..%d, %d random variants each", c.tagBits, c.maxVariants, idx.MaxTag(), span)
+		if _, err := m.EncodeTagged(idx.MaxTag()+1, uint32(1)); err == nil {
+			t.Fatal("expected tag range error")
+		}
+		if _, err := m.EncodeTagged(-1, uint32(1)); err == nil {
+			t.Fatal("expected negative tag error")
+		}
+	}
+}
+
+func TestTagBitsDefaults(t *testing.T) {
+	m, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := m.EncodeTagged(0, int64(-7))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tag, _, err := m.DecodeTagged(s); err != nil || tag != 0 {
+		t.Fatalf("tag %d %v", tag, err)
+	}
+	if _, err := m.EncodeTagged(1, int64(-7)); err == nil {
+		t.Fatal("tagBits=0 only allows tag 0")
+	}
+
+	// Encode 在启用 tag 时总是写入 tag 0
+	idx, _ := NewIdx(WithTagBits(2))
+	tagged, _ := New(WithIdx(idx))
+	for i := 0; i < 100; i++ {
+		s, _ := tagged.Encode(uint8(1))
+		if tag, _, err := tagged.DecodeTagged(s); err != nil || tag != 0 {
+			t.Fatalf("Encode tag %d %v", tag, err)
+		}
+	}
+	// tag 与 kind 可同时使用
+	users := tagged.ForKind("user")
+	s, _ = users.EncodeTagged(3, uint8(1))
+	if tag, _, err := users.DecodeTagged(s); err != nil || tag != 3 {
+		t.Fatalf("ForKind tag %d %v", tag, err)
+	}
+
+	for _, opts := range [][]IdxOption{
+		{WithTagBits(6)},
+		{WithTagBits(-1)},
+		{WithMaxVariants(12), WithTagBits(3)},
+		{WithTagBits(1), WithMaxVariants(1)},
+	} {
+		if _, err := NewIdx(opts...); err == nil {
+			t.Fatalf("%d options: expected error", len(opts))
+		} else {
+			t.Logf("NewIdx => %v", err)
+		}
+	}
+}
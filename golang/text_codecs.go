@@ -0,0 +1,208 @@
+// text_codecs.go 提供常见标准文本编码的内置 Codec：URL 安全无填充 Base64、
+// RFC 4648 Base32、Crockford Base32、比特币 Base58 与 ZeroMQ Z85。
+//
+// 与 RadixCodec 不同，这些编码的输出与各自规范（及其他语言的标准实现）逐字节一致，
+// 适合与外部系统交换或沿用既有格式；均可用于 WithCodec 与 EncodeBytes。
+package idmix
+
+import (
+	"encoding/base32"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Base64URLCodec 使用 RFC 4648 第 5 节 base64url 字符表（`-` `_`）且不带 `=` 填充，输出可直接放入 URL。
+type Base64URLCodec struct{}
+
+func NewBase64URLCodec() Base64URLCodec { return Base64URLCodec{} }
+
+func (Base64URLCodec) Encode(data []byte) (string, error) {
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func (Base64URLCodec) Decode(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(s)
+}
+
+// Base32Codec 使用 RFC 4648 第 6 节标准 Base32（`A-Z 2-7`，带 `=` 填充）。
+type Base32Codec struct{}
+
+func NewBase32Codec() Base32Codec { return Base32Codec{} }
+
+func (Base32Codec) Encode(data []byte) (string, error) {
+	return base32.StdEncoding.EncodeToString(data), nil
+}
+
+func (Base32Codec) Decode(s string) ([]byte, error) {
+	return base32.StdEncoding.DecodeString(s)
+}
+
+// crockfordEncoding 为 Crockford Base32 字符表（即 AlphabetCrockford32），无填充。
+var crockfordEncoding = base32.NewEncoding(AlphabetCrockford32).WithPadding(base32.NoPadding)
+
+// CrockfordCodec 使用 Crockford Base32：输出大写、无填充；
+// 解码不区分大小写，将 O 视为 0、I 与 L 视为 1，并忽略连字符 `-`。
+// 不包含 Crockford 可选的校验字符（需要校验时见 HumanCodec 或 WithChecksum）。
+type CrockfordCodec struct{}
+
+func NewCrockfordCodec() CrockfordCodec { return CrockfordCodec{} }
+
+func (CrockfordCodec) Encode(data []byte) (string, error) {
+	return crockfordEncoding.EncodeToString(data), nil
+}
+
+func (CrockfordCodec) Decode(s string) ([]byte, error) {
+	return crockfordEncoding.DecodeString(crockfordReplacer.Replace(strings.ToUpper(s)))
+}
+
+var crockfordReplacer = strings.NewReplacer("O", "0", "I", "1", "L", "1", "-", "")
+
+// AlphabetBase58 为比特币 Base58 字符表（去掉 0 O I l）。
+const AlphabetBase58 = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+var base58Index = func() (idx [256]int8) {
+	for i := range idx {
+		idx[i] = -1
+	}
+	for i := 0; i < len(AlphabetBase58); i++ {
+		idx[AlphabetBase58[i]] = int8(i)
+	}
+	return
+}()
+
+// Base58Codec 使用比特币 Base58：整体按大整数转换为 58 进制，每个前导 0x00 字节输出一个 `1`。
+// 不含 Base58Check 的版本字节与校验和。转换耗时随长度平方增长，适合短载荷。
+type Base58Codec struct{}
+
+func NewBase58Codec() Base58Codec { return Base58Codec{} }
+
+func (Base58Codec) Encode(data []byte) (string, error) {
+	zeros := 0
+	for zeros < len(data) && data[zeros] == 0 {
+		zeros++
+	}
+	// 58 进制位数上限：len * log(256)/log(58) ≈ len * 1.366
+	digits := make([]byte, 0, (len(data)-zeros)*138/100+1)
+	for _, b := range data[zeros:] {
+		carry := int(b)
+		for i := range digits {
+			carry += int(digits[i]) << 8
+			digits[i] = byte(carry % 58)
+			carry /= 58
+		}
+		for carry > 0 {
+			digits = append(digits, byte(carry%58))
+			carry /= 58
+		}
+	}
+	out := make([]byte, zeros+len(digits))
+	for i := 0; i < zeros; i++ {
+		out[i] = AlphabetBase58[0]
+	}
+	for i, d := range digits {
+		out[len(out)-1-i] = AlphabetBase58[d]
+	}
+	return string(out), nil
+}
+
+func (Base58Codec) Decode(s string) ([]byte, error) {
+	zeros := 0
+	for zeros < len(s) && s[zeros] == AlphabetBase58[0] {
+		zeros++
+	}
+	// 字节数上限：len * log(58)/log(256) ≈ len * 0.733
+	bytes := make([]byte, 0, (len(s)-zeros)*733/1000+1)
+	for i := zeros; i < len(s); i++ {
+		v := base58Index[s[i]]
+		if v < 0 {
+			return nil, fmt.Errorf("invalid base58 character %q at %d", s[i], i)
+		}
+		carry := int(v)
+		for j := range bytes {
+			carry += int(bytes[j]) * 58
+			bytes[j] = byte(carry)
+			carry >>= 8
+		}
+		for carry > 0 {
+			bytes = append(bytes, byte(carry))
+			carry >>= 8
+		}
+	}
+	out := make([]byte, zeros+len(bytes))
+	for i, b := range bytes {
+		out[len(out)-1-i] = b
+	}
+	return out, nil
+}
+
+// AlphabetZ85 为 ZeroMQ Z85（RFC 32/Z85）字符表。
+const AlphabetZ85 = "0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ.-:+=^!/*?&<>()[]{}@%$#"
+
+var z85Index = func() (idx [256]int8) {
+	for i := range idx {
+		idx[i] = -1
+	}
+	for i := 0; i < len(AlphabetZ85); i++ {
+		idx[AlphabetZ85[i]] = int8(i)
+	}
+	return
+}()
+
+// ErrZ85Length Z85 串长度除以 5 余 1，不对应任何字节数。
+var ErrZ85Length = errors.New("invalid z85 length")
+
+// Z85Codec 使用 ZeroMQ Z85：每 4 字节大端整数输出 5 个 85 进制字符。
+//
+// 规范只定义 4 字节整数倍的输入，此时输出与规范逐字节一致。IDX 二进制长度任意，
+// 因此末尾 n（1~3）字节按 Ascii85 惯例补零成一组、只输出前 n+1 个字符；
+// 解码时以 `#`（最大值）补齐后截取。需要严格规范行为时先检查 len(data)%4 == 0。
+type Z85Codec struct{}
+
+func NewZ85Codec() Z85Codec { return Z85Codec{} }
+
+func (Z85Codec) Encode(data []byte) (string, error) {
+	out := make([]byte, 0, (len(data)*5+3)/4)
+	for i := 0; i < len(data); i += 4 {
+		var group [4]byte
+		n := copy(group[:], data[i:])
+		v := uint32(group[0])<<24 | uint32(group[1])<<16 | uint32(group[2])<<8 | uint32(group[3])
+		var chars [5]byte
+		for j := 4; j >= 0; j-- {
+			chars[j] = AlphabetZ85[v%85]
+			v /= 85
+		}
+		out = append(out, chars[:n+1]...)
+	}
+	return string(out), nil
+}
+
+func (Z85Codec) Decode(s string) ([]byte, error) {
+	if len(s)%5 == 1 {
+		return nil, fmt.Errorf("%w %d", ErrZ85Length, len(s))
+	}
+	out := make([]byte, 0, len(s)*4/5)
+	for i := 0; i < len(s); i += 5 {
+		n := len(s) - i
+		if n > 5 {
+			n = 5
+		}
+		var v uint64
+		for j := 0; j < 5; j++ {
+			d := int8(len(AlphabetZ85) - 1)
+			if j < n {
+				if d = z85Index[s[i+j]]; d < 0 {
+					return nil, fmt.Errorf("invalid z85 character %q at %d", s[i+j], i+j)
+				}
+			}
+			v = v*85 + uint64(d)
+		}
+		if v > 0xFFFFFFFF {
+			return nil, fmt.Errorf("z85 group at %d overflows 32 bits", i)
+		}
+		group := [4]byte{byte(v >> 24), byte(v >> 16), byte(v >> 8), byte(v)}
+		out = append(out, group[:n-1]...)
+	}
+	return out, nil
+}
@@ -0,0 +1,179 @@
+// text_codecs_test.go 以各规范的测试向量覆盖内置标准编码，并验证可用于 IdMix 与 Profile。
+package idmix
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"math/rand"
+	"testing"
+)
+
+type textVector struct {
+	data    string
+	encoded string
+}
+
+func checkTextVectors(t *testing.T, c Codec, vectors []textVector) {
+	t.Helper()
+	for _, v := range vectors {
+		s, err := c.Encode([]byte(v.data))
+		if err != nil || s != v.encoded {
+			t.Fatalf("%T Encode(%q) = %q %v, want %q", c, v.data, s, err, v.encoded)
+		}
+		back, err := c.Decode(s)
+		if err != nil || string(back) != v.data {
+			t.Fatalf("%T Decode(%q) = %q %v", c, s, back, err)
+		}
+		t.Logf("%T %q => %q", c, v.data, s)
+	}
+}
+
+// RFC 4648 第 10 节测试向量。
+var rfc4648Inputs = []string{"", "f", "fo", "foo", "foob", "fooba", "foobar"}
+
+func TestBase64URLVectors(t *testing.T) {
+	want := []string{"", "Zg", "Zm8", "Zm9v", "Zm9vYg", "Zm9vYmE", "Zm9vYmFy"}
+	var vectors []textVector
+	for i, in := range rfc4648Inputs {
+		vectors = append(vectors, textVector{in, want[i]})
+	}
+	vectors = append(vectors, textVector{"\xfb\xff\xbf", "-_-_"})
+	checkTextVectors(t, NewBase64URLCodec(), vectors)
+	if _, err := NewBase64URLCodec().Decode("Zg=="); err == nil {
+		t.Fatal("padding should be rejected")
+	}
+}
+
+func TestBase32Vectors(t *testing.T) {
+	want := []string{"", "MY======", "MZXQ====", "MZXW6===", "MZXW6YQ=", "MZXW6YTB", "MZXW6YTBOI======"}
+	var vectors []textVector
+	for i, in := range rfc4648Inputs {
+		vectors = append(vectors, textVector{in, want[i]})
+	}
+	checkTextVectors(t, NewBase32Codec(), vectors)
+}
+
+func TestCrockfordVectors(t *testing.T) {
+	want := []string{"", "CR", "CSQG", "CSQPY", "CSQPYRG", "CSQPYRK1", "CSQPYRK1E8"}
+	var vectors []textVector
+	for i, in := range rfc4648Inputs {
+		vectors = append(vectors, textVector{in, want[i]})
+	}
+	vectors = append(vectors, textVector{"Hello, World!", "91JPRV3F5GG5EVVJDHJ22"})
+	c := NewCrockfordCodec()
+	checkTextVectors(t, c, vectors)
+
+	// 解码不区分大小写，O→0、I/L→1，忽略连字符
+	for _, s := range []string{"csqpyrk1e8", "CSQP-YRK1-E8", "csqpyrkie8", "csqpyrkLe8"} {
+		back, err := c.Decode(s)
+		if err != nil || string(back) != "foobar" {
+			t.Fatalf("Decode(%q) = %q %v", s, back, err)
+		}
+	}
+	if back, err := c.Decode("o0"); err != nil || !bytes.Equal(back, []byte{0}) {
+		t.Fatalf("Decode(o0) = %x %v", back, err)
+	}
+	if _, err := c.Decode("CU"); err == nil {
+		t.Fatal("U is not a Crockford character")
+	}
+}
+
+// TestBase58Vectors 向量取自 draft-msporny-base58 与比特币实现。
+func TestBase58Vectors(t *testing.T) {
+	checkTextVectors(t, NewBase58Codec(), []textVector{
+		{"", ""},
+		{"Hello World!", "2NEpo7TZRRrLZSi2U"},
+		{"The quick brown fox jumps over the lazy dog.", "USm3fpXnKG5EUBx2ndxBDMPVciP5hGey2Jh4NDv6gmeo1LkMeiKrLJUUBk6Z"},
+		{"\x00\x00\x28\x7f\xb4\xcd", "11233QC4"},
+		{"\x00", "1"},
+		{"\x00\x00\x00", "111"},
+		{"\x61", "2g"},
+		{"\xff", "5Q"},
+	})
+	for _, s := range []string{"0", "O", "I", "l", "2N+"} {
+		if _, err := NewBase58Codec().Decode(s); err == nil {
+			t.Fatalf("Decode(%q) should fail", s)
+		}
+	}
+}
+
+// TestZ85Vectors 向量取自 ZeroMQ RFC 32/Z85；非 4 字节整数倍为本库的 Ascii85 式扩展。
+func TestZ85Vectors(t *testing.T) {
+	spec, _ := hex.DecodeString("864FD26FB559F75B")
+	checkTextVectors(t, NewZ85Codec(), []textVector{
+		{"", ""},
+		{string(spec), "HelloWorld"},
+		{"\x00\x00\x00\x00", "00000"},
+		{"\xff\xff\xff\xff", "%nSc0"},
+	})
+	c := NewZ85Codec()
+	r := rand.New(rand.NewSource(85))
+	for n := 0; n <= 40; n++ {
+		data := make([]byte, n)
+		r.Read(data)
+		s, _ := c.Encode(data)
+		if len(s) != (n*5+3)/4 {
+			t.Fatalf("n=%d: length %d", n, len(s))
+		}
+		back, err := c.Decode(s)
+		if err != nil || !bytes.Equal(back, data) {
+			t.Fatalf("n=%d: %x %v", n, back, err)
+		}
+	}
+	if _, err := c.Decode("HelloW"); !errors.Is(err, ErrZ85Length) {
+		t.Fatalf("length 6: %v", err)
+	}
+	for _, s := range []string{"%nSc1", "Hello~", "#####"} {
+		if _, err := c.Decode(s); err == nil {
+			t.Fatalf("Decode(%q) should fail", s)
+		}
+	}
+}
+
+// TestTextCodecsWithIdMix 每个内置编码都能作为 IdMix 文本层，并可由 Profile 名称构造。
+func TestTextCodecsWithIdMix(t *testing.T) {
+	codecs := map[string]Codec{
+		CodecBase64URL:   NewBase64URLCodec(),
+		CodecBase32:      NewBase32Codec(),
+		CodecCrockford32: NewCrockfordCodec(),
+		CodecBase58:      NewBase58Codec(),
+		CodecZ85:         NewZ85Codec(),
+	}
+	for name, c := range codecs {
+		m, err := New(WithCodec(c))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, values := range [][]any{{uint8(1)}, {uint32(1001), "eu"}, {uint64(1<<64 - 1), int16(-7), "订单"}} {
+			s, err := m.Encode(values...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			list, err := m.Decode(s)
+			if err != nil || len(list) != len(values) {
+				t.Fatalf("%s: %q => %v %v", name, s, list, err)
+			}
+			t.Logf("%-12s %v => %q", name, values, s)
+		}
+
+		fromProfile, err := NewFromProfile(Profile{Codec: name}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		a, _ := m.EncodeWithVariant(3, uint32(42))
+		b, _ := fromProfile.EncodeWithVariant(3, uint32(42))
+		if a != b {
+			t.Fatalf("%s: profile %q, option %q", name, b, a)
+		}
+		if err := (Profile{Codec: name, Alphabet: "abc"}).Validate(); err == nil {
+			t.Fatalf("%s: alphabet should be rejected", name)
+		}
+		data := []byte{0, 1, 2, 0xfe, 0xff}
+		s, _ := EncodeBytes(data, c)
+		back, err := DecodeString(s, c)
+		if err != nil || !bytes.Equal(back, data) {
+			t.Fatalf("%s: EncodeBytes round trip %x %v", name, back, err)
+		}
+	}
+}
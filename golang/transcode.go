@@ -0,0 +1,75 @@
+// transcode.go 实现配置迁移时的转码：用旧配置解码、新配置重新编码，值（含类型与 tag）保持不变。
+//
+// 适用于更换字符表、Codec、checkBits、校验方式等场景下批量改写已存储的编码串；
+// 批量处理见命令行工具 cmd/idmix 的 migrate 子命令。
+package idmix
+
+import (
+	"errors"
+	"fmt"
+)
+
+// TranscodeOption 配置 Transcode。
+type TranscodeOption func(*transcodeConfig) error
+
+type transcodeConfig struct {
+	keepVariant bool
+}
+
+// KeepVariant 使 Transcode 保留原编码串的 variant_id（默认在目标配置下随机选取）。
+// 原 variant 超出目标的 maxVariants，或在目标配置下对应的 tag 不同时返回错误。
+func KeepVariant() TranscodeOption {
+	return func(c *transcodeConfig) error {
+		c.keepVariant = true
+		return nil
+	}
+}
+
+// ErrTranscodeTag 原编码串的 tag 无法在目标配置中表示。
+var ErrTranscodeTag = errors.New("tag cannot be represented in target configuration")
+
+// Transcode 用 from 解码 s，再用 to 重新编码，值、tag 与有效期标记保持不变。
+//
+// 解码失败的错误以 "decode: " 开头，编码失败的以 "encode: " 开头；
+// from 的 DecodeLimits、严格模式与 kind 检查照常生效。
+func Transcode(s string, from, to *IdMix, opts ...TranscodeOption) (string, error) {
+	if from == nil || to == nil {
+		return "", errors.New("transcode: from and to cannot be nil")
+	}
+	var cfg transcodeConfig
+	for _, opt := range opts {
+		if err := opt(&cfg); err != nil {
+			return "", err
+		}
+	}
+
+	data, values, err := from.decodeObserved(nil, OpTranscode, s)
+	if err != nil {
+		return "", fmt.Errorf("decode: %w", err)
+	}
+	variantID, err := from.idx.VariantOf(data)
+	if err != nil {
+		return "", fmt.Errorf("decode: %w", err)
+	}
+	tag := variantID / from.idx.tagSpan()
+
+	if tag > to.idx.MaxTag() {
+		return "", fmt.Errorf("encode: %w: tag %d (max %d)", ErrTranscodeTag, tag, to.idx.MaxTag())
+	}
+	if cfg.keepVariant {
+		if variantID >= to.idx.maxVariants {
+			return "", fmt.Errorf("encode: variant %d exceeds target maxVariants %d", variantID, to.idx.maxVariants)
+		}
+		if got := variantID / to.idx.tagSpan(); got != tag {
+			return "", fmt.Errorf("encode: %w: variant %d carries tag %d in target, want %d", ErrTranscodeTag, variantID, got, tag)
+		}
+	} else if variantID, err = to.idx.taggedVariant(tag, to.randIntn); err != nil {
+		return "", fmt.Errorf("encode: %w", err)
+	}
+
+	out, err := to.encodeVariant(nil, variantID, values, from.idx.hasTTL(data))
+	if err != nil {
+		return "", fmt.Errorf("encode: %w", err)
+	}
+	return out, nil
+}
@@ -0,0 +1,125 @@
+// transcode_test.go 覆盖配置间转码：值与 tag 保持不变、KeepVariant 保留 variant，以及各类失败。
+package idmix
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestTranscode 更换字符表、checkBits、Codec 与校验方式后值保持不变，且可再转回原配置。
+func TestTranscode(t *testing.T) {
+	oldIdx, _ := NewIdx(WithCheckBits(1))
+	old, _ := New(WithIdx(oldIdx))
+	newIdx, _ := NewIdx(WithCheckBits(2), WithChecksum(ChecksumCRC8))
+	targets := map[string]*IdMix{}
+	targets["crockford"], _ = New(WithIdx(newIdx), WithCodec(mustRadix(t, AlphabetCrockford32)))
+	targets["base64url"], _ = New(WithIdx(newIdx), WithCodec(NewBase64URLCodec()))
+	compact, _ := NewCompactRadixCodec(AlphabetLower36)
+	targets["compact"], _ = New(WithCodec(compact))
+
+	values := []any{uint32(1001), "eu", int64(-5), uint64(1 << 63)}
+	for name, to := range targets {
+		for i := 0; i < 16; i++ {
+			s := mustEncode(t, old, values...)
+			out, err := Transcode(s, old, to)
+			if err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+			got, err := to.Decode(out)
+			if err != nil || !reflect.DeepEqual(got, values) {
+				t.Fatalf("%s: %q => %q => %v %v", name, s, out, got, err)
+			}
+			back, err := Transcode(out, to, old)
+			if err != nil || !old.Equal(back, s) {
+				t.Fatalf("%s: round trip %q => %q %v", name, out, back, err)
+			}
+			if i == 0 {
+				t.Logf("%-10s %s => %s", name, s, out)
+			}
+		}
+	}
+}
+
+// TestTranscodeVariant 默认随机选取 variant 但保留 tag；KeepVariant 保留 variant_id。
+func TestTranscodeVariant(t *testing.T) {
+	tagged, _ := NewIdx(WithMaxVariants(32), WithTagBits(2))
+	from, _ := New(WithIdx(tagged))
+	to, _ := New(WithIdx(tagged), WithCodec(NewBase64URLCodec()))
+	for v := 0; v < 32; v++ {
+		s, _ := from.EncodeWithVariant(v, uint32(7))
+		out, err := Transcode(s, from, to, KeepVariant())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ins, _ := to.Inspect(out); ins.VariantID != v {
+			t.Fatalf("variant %d => %d", v, ins.VariantID)
+		}
+		out, err = Transcode(s, from, to)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tag, _, _ := to.DecodeTagged(out); tag != v/8 {
+			t.Fatalf("variant %d: tag %d lost", v, v/8)
+		}
+	}
+
+	// 目标 variant 空间更小或 tag 布局不同
+	small, _ := NewIdx(WithMaxVariants(8))
+	toSmall, _ := New(WithIdx(small))
+	plain, _ := New()
+	s, _ := from.EncodeWithVariant(20, uint32(7)) // tag 2
+	if _, err := Transcode(s, from, plain); !errors.Is(err, ErrTranscodeTag) {
+		t.Fatalf("tag dropped silently: %v", err)
+	}
+	plainToken, _ := plain.EncodeWithVariant(20, uint32(7))
+	if _, err := Transcode(plainToken, plain, toSmall, KeepVariant()); err == nil || !strings.HasPrefix(err.Error(), "encode: ") {
+		t.Fatalf("variant beyond target maxVariants: %v", err)
+	}
+	if _, err := Transcode(plainToken, plain, from, KeepVariant()); !errors.Is(err, ErrTranscodeTag) {
+		t.Fatalf("variant with a different tag in target: %v", err)
+	}
+	if out, err := Transcode(plainToken, plain, toSmall); err != nil {
+		t.Fatal(err)
+	} else {
+		t.Logf("variant 20 => %s (random variant below 8)", out)
+	}
+}
+
+// TestTranscodeErrors 解码失败以 "decode: " 开头，编码失败以 "encode: " 开头，并保留原始错误。
+func TestTranscodeErrors(t *testing.T) {
+	from, _ := New()
+	smallIdx, _ := NewIdx(WithMaxObjects(1))
+	to, _ := New(WithIdx(smallIdx))
+
+	if _, err := Transcode("!!", from, to); err == nil || !strings.HasPrefix(err.Error(), "decode: ") {
+		t.Fatalf("bad input: %v", err)
+	}
+	two := mustEncode(t, from, uint8(1), uint8(2))
+	if _, err := Transcode(two, from, to); err == nil || !strings.HasPrefix(err.Error(), "encode: ") {
+		t.Fatalf("too many objects for target: %v", err)
+	}
+	limited, _ := New(WithDecodeLimits(DecodeLimits{MaxChars: 4}))
+	if _, err := Transcode(two, limited, to); !errors.Is(err, ErrLimitExceeded) {
+		t.Fatalf("limits: %v", err)
+	}
+	// 2 位校验下其他 kind 的编码串约 1/4 会通过校验，逐 variant 检查被拒绝的都报告 kind 不符
+	users := from.ForKind("user")
+	rejected := 0
+	for v := 0; v < 32; v++ {
+		s, _ := from.EncodeWithVariant(v, uint8(1), uint8(2))
+		if _, err := Transcode(s, users, from); err != nil {
+			if !errors.Is(err, ErrWrongKind) {
+				t.Fatalf("kind: %v", err)
+			}
+			rejected++
+		}
+	}
+	if rejected == 0 {
+		t.Fatal("no token was rejected as another kind")
+	}
+	if _, err := Transcode(two, nil, to); err == nil {
+		t.Fatal("nil from")
+	}
+}
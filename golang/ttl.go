@@ -0,0 +1,138 @@
+// ttl.go 实现带有效期的编码串：在 IDX 块末尾追加签发时间与有效期两个对象，
+// 并在扩展头 ext 字节中置有效期标记（bit4，见 checksum.go）；解码时与调用方值分离并校验时间窗口。
+//
+// 时间元数据对象（按普通整数对象编码，宽度随数值自动压缩）：
+//
+//	[...用户值] + uint64(issued_at, Unix 秒) + uint32(ttl, 秒)
+//
+// 只有带标记的块才被视为有效期编码串，末尾恰好是 uint64、uint32 的普通编码串不会被误读。
+// 典型开销 10 字节（扩展头 2、issued_at 1+4、ttl 1+1~2）。
+package idmix
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+var (
+	// ErrExpired 当前时间已超过有效期（已计入时钟偏差）。
+	ErrExpired = errors.New("token expired")
+	// ErrNotYetValid 签发时间晚于当前时间（已计入时钟偏差）。
+	ErrNotYetValid = errors.New("token not yet valid")
+	// ErrNoTTL 编码串不带有效期标记，不是 EncodeWithTTL 的输出。
+	ErrNoTTL = errors.New("token has no ttl metadata")
+)
+
+// ttlObjects 为时间元数据占用的对象个数。
+const ttlObjects = 2
+
+// TimedValues 为 DecodeValid 的结果，用户值与时间元数据分开存放。
+type TimedValues struct {
+	Values    []any
+	IssuedAt  time.Time
+	ExpiresAt time.Time
+}
+
+// WithClock 设置 EncodeWithTTL 与 DecodeValidNow 使用的时钟（默认 time.Now），便于测试注入。
+func WithClock(now func() time.Time) Option {
+	return func(m *IdMix) error {
+		if now == nil {
+			return errors.New("clock cannot be nil")
+		}
+		m.now = now
+		return nil
+	}
+}
+
+// WithClockSkew 设置校验有效期时容忍的时钟偏差（默认 0）。
+func WithClockSkew(skew time.Duration) Option {
+	return func(m *IdMix) error {
+		if skew < 0 {
+			return errors.New("clock skew cannot be negative")
+		}
+		m.skew = skew
+		return nil
+	}
+}
+
+// EncodeWithTTL 编码 values 并附带签发时间（当前时钟）与有效期 ttl（按秒向上取整）。
+func (m *IdMix) EncodeWithTTL(ttl time.Duration, values ...any) (string, error) {
+	if len(values) < 1 {
+		return m.encodeRejected(errors.New("at least one value is required"))
+	}
+	if len(values)+ttlObjects > m.idx.maxObjects {
+		return m.encodeRejected(fmt.Errorf("too many objects: %d + %d ttl objects (max %d)", len(values), ttlObjects, m.idx.maxObjects))
+	}
+	if ttl <= 0 {
+		return m.encodeRejected(fmt.Errorf("ttl must be positive, got %v", ttl))
+	}
+	secs := (ttl + time.Second - 1) / time.Second
+	if secs > math.MaxUint32 {
+		return m.encodeRejected(fmt.Errorf("ttl %v exceeds max %ds", ttl, uint32(math.MaxUint32)))
+	}
+	issuedAt := m.clock().Unix()
+	if issuedAt < 0 {
+		return m.encodeRejected(fmt.Errorf("issued-at %d is before the Unix epoch", issuedAt))
+	}
+	variantID, err := m.idx.taggedVariant(0, m.randIntn)
+	if err != nil {
+		return m.encodeRejected(err)
+	}
+	all := make([]any, 0, len(values)+ttlObjects)
+	all = append(all, values...)
+	all = append(all, uint64(issuedAt), uint32(secs))
+	return m.encodeVariant(nil, variantID, all, true)
+}
+
+// DecodeValid 解码 EncodeWithTTL 的输出，并以 now 校验有效期。
+//
+// 过期或尚未生效时同时返回结果与 ErrExpired / ErrNotYetValid，便于调用方记录；
+// 其他错误时结果为 nil。
+func (m *IdMix) DecodeValid(s string, now time.Time) (*TimedValues, error) {
+	var tv *TimedValues
+	_, _, err := m.decodeChecked(OpDecodeValid, s, func(s string) ([]byte, error) { return m.decodeText(nil, s) },
+		func(data []byte, list []any) (err error) {
+			tv, err = m.timedValues(data, list, now)
+			return err
+		})
+	return tv, err
+}
+
+// timedValues 从解码结果中拆出有效期元数据并以 now 校验；过期或尚未生效时同时返回结果与错误。
+func (m *IdMix) timedValues(data []byte, list []any, now time.Time) (*TimedValues, error) {
+	if !m.idx.hasTTL(data) {
+		return nil, ErrNoTTL
+	}
+	n := len(list)
+	issuedAt, ok1 := list[n-2].(uint64)
+	ttl, ok2 := list[n-1].(uint32)
+	if !ok1 || !ok2 || issuedAt > math.MaxInt64 {
+		return nil, fmt.Errorf("invalid ttl metadata: %T(%v), %T(%v)", list[n-2], list[n-2], list[n-1], list[n-1])
+	}
+	tv := &TimedValues{
+		Values:   list[:n-ttlObjects],
+		IssuedAt: time.Unix(int64(issuedAt), 0).UTC(),
+	}
+	tv.ExpiresAt = tv.IssuedAt.Add(time.Duration(ttl) * time.Second)
+	if now.Add(m.skew).Before(tv.IssuedAt) {
+		return tv, ErrNotYetValid
+	}
+	if !now.Add(-m.skew).Before(tv.ExpiresAt) {
+		return tv, ErrExpired
+	}
+	return tv, nil
+}
+
+// DecodeValidNow 同 DecodeValid，使用实例时钟（WithClock）的当前时间。
+func (m *IdMix) DecodeValidNow(s string) (*TimedValues, error) {
+	return m.DecodeValid(s, m.clock())
+}
+
+func (m *IdMix) clock() time.Time {
+	if m.now == nil {
+		return time.Now()
+	}
+	return m.now()
+}
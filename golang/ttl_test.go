@@ -0,0 +1,191 @@
+// ttl_test.go 覆盖有效期编码、时钟偏差与时间元数据分离。
+package idmix
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEncodeWithTTL(t *testing.T) {
+	issued := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	m, err := New(WithClock(func() time.Time { return issued }), WithClockSkew(30*time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := m.EncodeWithTTL(time.Hour, uint32(1001), uint8(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain, _ := m.Encode(uint32(1001), uint8(3))
+	t.Logf("TTL 编码串: %q (len=%d)，无 TTL: %q (len=%d)", s, len(s), plain, len(plain))
+
+	cases := []struct {
+		name string
+		now  time.Time
+		want error
+	}{
+		{"at_issue", issued, nil},
+		{"just_before_expiry", issued.Add(time.Hour - time.Second), nil},
+		{"within_skew_after_expiry", issued.Add(time.Hour + 29*time.Second), nil},
+		{"expired", issued.Add(time.Hour + 30*time.Second), ErrExpired},
+		{"within_skew_before_issue", issued.Add(-30 * time.Second), nil},
+		{"not_yet_valid", issued.Add(-31 * time.Second), ErrNotYetValid},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			tv, err := m.DecodeValid(s, c.now)
+			if !errors.Is(err, c.want) {
+				t.Fatalf("err = %v, want %v", err, c.want)
+			}
+			if tv == nil {
+				t.Fatal("result should be returned together with time errors")
+			}
+			if len(tv.Values) != 2 || tv.Values[0].(uint32) != 1001 || tv.Values[1].(uint8) != 3 {
+				t.Fatalf("values = %v", tv.Values)
+			}
+			if !tv.IssuedAt.Equal(issued) || !tv.ExpiresAt.Equal(issued.Add(time.Hour)) {
+				t.Fatalf("issued %v expires %v", tv.IssuedAt, tv.ExpiresAt)
+			}
+		})
+	}
+
+	if _, err := m.DecodeValidNow(s); err != nil {
+		t.Fatalf("DecodeValidNow with injected clock: %v", err)
+	}
+	list, err := m.Decode(s)
+	if err != nil || len(list) != 4 {
+		t.Fatalf("plain Decode sees metadata objects: %v %v", list, err)
+	}
+}
+
+// TestEncodeMaxObjects IdMix 的各编码入口都在编码时拒绝超出 maxObjects 的值（有效期元数据计入个数），
+// 而不是产出本配置无法解码的编码串。
+func TestEncodeMaxObjects(t *testing.T) {
+	idx, err := NewIdx(WithMaxObjects(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := New(WithIdx(idx))
+	if err != nil {
+		t.Fatal(err)
+	}
+	over := []any{uint8(1), uint8(2), uint8(3), uint8(4)}
+	if _, err := m.Encode(over...); err == nil || !strings.Contains(err.Error(), "too many objects") {
+		t.Fatalf("Encode: %v", err)
+	}
+	if _, err := m.EncodeWithVariant(0, over...); err == nil || !strings.Contains(err.Error(), "too many objects") {
+		t.Fatalf("EncodeWithVariant: %v", err)
+	}
+	if _, err := m.EncodeWithTTL(time.Hour, over[:2]...); err == nil || !strings.Contains(err.Error(), "too many objects") {
+		t.Fatalf("EncodeWithTTL: %v", err)
+	}
+	s, err := m.EncodeWithTTL(time.Hour, over[:1]...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.DecodeValidNow(s); err != nil {
+		t.Fatal(err)
+	}
+	t.Logf("max=3: 1 value + ttl => %s", s)
+}
+
+func TestEncodeWithTTLErrors(t *testing.T) {
+	m, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.EncodeWithTTL(0, uint8(1)); err == nil {
+		t.Fatal("expected non-positive ttl error")
+	}
+	if _, err := m.EncodeWithTTL(time.Hour); err == nil {
+		t.Fatal("expected empty values error")
+	}
+	idx, _ := NewIdx(WithMaxObjects(3))
+	small, _ := New(WithIdx(idx))
+	if _, err := small.EncodeWithTTL(time.Hour, uint8(1), uint8(2)); err == nil {
+		t.Fatal("expected maxObjects error")
+	}
+	if _, err := New(WithClock(nil)); err == nil {
+		t.Fatal("expected nil clock error")
+	}
+	if _, err := New(WithClockSkew(-time.Second)); err == nil {
+		t.Fatal("expected negative skew error")
+	}
+
+	plain, _ := m.Encode(uint32(1001), uint64(5), uint8(3))
+	if _, err := m.DecodeValid(plain, time.Now()); !errors.Is(err, ErrNoTTL) {
+		t.Fatalf("err = %v, want ErrNoTTL", err)
+	}
+	short, _ := m.Encode(uint64(5), uint32(3))
+	if _, err := m.DecodeValid(short, time.Now()); !errors.Is(err, ErrNoTTL) {
+		t.Fatalf("err = %v, want ErrNoTTL", err)
+	}
+
+	s, err := m.EncodeWithTTL(1500*time.Millisecond, "k")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tv, err := m.DecodeValidNow(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := tv.ExpiresAt.Sub(tv.IssuedAt); got != 2*time.Second {
+		t.Fatalf("ttl rounded to %v, want 2s", got)
+	}
+}
+
+// TestTTLMarker 有效期由扩展头标记识别：末尾恰好是 uint64、uint32 的普通编码串不会被当作有效期编码串，
+// Canonical、Transcode 保留标记，Equal 区分同值的两种编码串。
+func TestTTLMarker(t *testing.T) {
+	issued := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	m, _ := New(WithClock(func() time.Time { return issued }))
+	lookalike, _ := m.Encode("order", uint64(issued.Unix()), uint32(3600))
+	if _, err := m.DecodeValid(lookalike, issued); !errors.Is(err, ErrNoTTL) {
+		t.Fatalf("plain token with a uint64/uint32 tail: err = %v, want ErrNoTTL", err)
+	}
+	ttl, _ := m.EncodeWithTTL(time.Hour, "order")
+	if tv, err := m.DecodeValid(ttl, issued); err != nil || len(tv.Values) != 1 {
+		t.Fatalf("DecodeValid: %+v %v", tv, err)
+	}
+	t.Logf("plain %q, ttl %q", lookalike, ttl)
+
+	if m.Equal(lookalike, ttl) {
+		t.Fatal("ttl token should not equal a plain token with the same values")
+	}
+	for _, c := range []struct {
+		s    string
+		want bool
+	}{{lookalike, false}, {ttl, true}} {
+		in, err := m.Inspect(c.s)
+		if err != nil || in.TTL != c.want {
+			t.Fatalf("Inspect(%q).TTL = %v, want %v (%v)", c.s, in.TTL, c.want, err)
+		}
+	}
+
+	canon, err := m.Canonical(ttl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	crcIdx, _ := NewIdx(WithChecksum(ChecksumCRC16))
+	target, _ := New(WithIdx(crcIdx))
+	moved, err := Transcode(ttl, m, target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, out := range map[string]struct {
+		m *IdMix
+		s string
+	}{"canonical": {m, canon}, "transcode": {target, moved}} {
+		if _, err := out.m.DecodeValid(out.s, issued); err != nil {
+			t.Fatalf("%s lost the ttl marker: %v", name, err)
+		}
+	}
+
+	// 带标记但对象不足三个的块无效
+	short := rawBlock(m.Idx(), 0, []byte{0x80, extMarker, extHasTTL | extHasCount, 2}, []byte{0x05, 0x06})
+	if _, err := m.Idx().Decode(short); err == nil {
+		t.Fatal("expected invalid ttl block error")
+	}
+}
@@ -0,0 +1,137 @@
+// version.go 实现 IDX 协议版本标记与按版本分派的对象解析（标记方式见 arithmetic.md 4.3 节）。
+//
+// 未带标记的块按 v1.2（当前 arithmetic.md 描述的格式）解析，与旧编码串及其他语言实现保持兼容；
+// 将来修改对象编码或头部布局时必须带版本标记，解码器据此选择解析器，
+// 遇到不认识的版本返回 ErrUnsupportedVersion 而不是静默误解。
+package idmix
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// IdxVersion 为 IDX 协议版本，高 4 位为主版本、低 4 位为次版本（0x12 即 v1.2）。
+type IdxVersion uint8
+
+const (
+	// IdxVersionUnmarked 表示不写版本标记（默认）；这类块按 IdxV12 解析。
+	IdxVersionUnmarked IdxVersion = 0
+	// IdxV12 为 arithmetic.md 描述的 IDX v1.2 格式。
+	IdxV12 IdxVersion = 0x12
+
+	// CurrentIdxVersion 为本实现编码所用的格式版本。
+	CurrentIdxVersion = IdxV12
+)
+
+func (v IdxVersion) String() string {
+	if v == IdxVersionUnmarked {
+		return "unmarked"
+	}
+	return fmt.Sprintf("v%d.%d", v>>4, v&0x0F)
+}
+
+// ParseIdxVersion 按名称（unmarked、v1.2 或 1.2）解析版本。
+func ParseIdxVersion(name string) (IdxVersion, error) {
+	if name == IdxVersionUnmarked.String() {
+		return IdxVersionUnmarked, nil
+	}
+	trimmed := strings.TrimPrefix(name, "v")
+	for v := range idxParsers {
+		if v.String() == "v"+trimmed {
+			return v, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown idx version %q", name)
+}
+
+// ErrUnsupportedVersion 块带有本实现不认识的版本标记（可用 errors.Is 判断）。
+var ErrUnsupportedVersion = errors.New("unsupported idx version")
+
+// UnsupportedVersionError 为 ErrUnsupportedVersion 的具体错误。
+type UnsupportedVersionError struct {
+	Version IdxVersion
+}
+
+func (e *UnsupportedVersionError) Error() string {
+	return fmt.Sprintf("%v %s (%#02x)", ErrUnsupportedVersion, e.Version, uint8(e.Version))
+}
+
+func (e *UnsupportedVersionError) Is(target error) bool { return target == ErrUnsupportedVersion }
+
+// objectParser 将还原后的对象区解析为 count 个数据对象，并按 idx 的上限与严格模式逐对象检查。
+type objectParser func(idx *Idx, objData []byte, count int) ([]dataObject, error)
+
+// idxParsers 为各版本的对象解析器；新增版本时在此注册。
+var idxParsers = map[IdxVersion]objectParser{
+	IdxV12: parseObjectsV12,
+}
+
+// WithVersion 固定编码时写入的版本标记，便于与只支持特定版本的其他语言实现互通。
+//
+// 默认 IdxVersionUnmarked 不写标记（与旧版输出逐字节相同）；指定版本时改用扩展头，额外 3 字节。
+// 解码不受影响：任何已支持版本（含未标记）的块都可解码。
+func WithVersion(v IdxVersion) IdxOption {
+	return func(idx *Idx) error {
+		if _, ok := idxParsers[v]; !ok && v != IdxVersionUnmarked {
+			return &UnsupportedVersionError{Version: v}
+		}
+		idx.version = v
+		return nil
+	}
+}
+
+// Version 返回编码时写入的版本标记（IdxVersionUnmarked 为不写）。
+func (idx *Idx) Version() IdxVersion { return idx.version }
+
+// VersionOf 读取块的格式版本（不做校验和与对象解析）；marked 报告块中是否带版本标记，
+// 未标记时 version 为 IdxV12。
+func (idx *Idx) VersionOf(data []byte) (version IdxVersion, marked bool, err error) {
+	h, err := idx.parseHeader(data)
+	if err != nil {
+		return 0, false, err
+	}
+	if h.version == IdxVersionUnmarked {
+		return IdxV12, false, nil
+	}
+	return h.version, true, nil
+}
+
+// DecodeVersioned 与 Decode 相同，另返回块的格式版本（未标记为 IdxV12）。
+func (idx *Idx) DecodeVersioned(data []byte) ([]any, IdxVersion, error) {
+	values, err := idx.Decode(data)
+	if err != nil {
+		return nil, 0, err
+	}
+	v, _, err := idx.VersionOf(data)
+	return values, v, err
+}
+
+// parseObjectsV12 按 v1.2 对象格式（arithmetic.md 2.2 节）顺序解析，要求恰好用尽对象区。
+func parseObjectsV12(idx *Idx, objData []byte, count int) ([]dataObject, error) {
+	result := make([]dataObject, 0, count)
+	pos, strBytes := 0, 0
+	for i := 0; i < count; i++ {
+		if pos >= len(objData) {
+			return nil, errors.New("premature end of data")
+		}
+		obj, n, err := decodeObject(objData[pos:])
+		if err != nil {
+			return nil, fmt.Errorf("object[%d]: %w", i, err)
+		}
+		if err := idx.limits.checkObject(obj, &strBytes); err != nil {
+			return nil, fmt.Errorf("object[%d]: %w", i, err)
+		}
+		if idx.strict {
+			if err := checkCanonicalObject(obj, objData[pos:pos+n]); err != nil {
+				return nil, fmt.Errorf("object[%d]: %w", i, err)
+			}
+		}
+		result = append(result, obj)
+		pos += n
+	}
+	if pos != len(objData) {
+		return nil, errors.New("extra bytes after data objects")
+	}
+	return result, nil
+}
@@ -0,0 +1,141 @@
+// version_test.go 覆盖 IDX 版本标记：默认输出不变、固定版本的向量、按版本解码与未知版本错误。
+package idmix
+
+import (
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+// TestVersionVectors 固定版本时的扩展头向量（单对象 uint8(10)，variant 0）。
+func TestVersionVectors(t *testing.T) {
+	cases := []struct {
+		name string
+		opts []IdxOption
+		want string
+	}{
+		{"unmarked", nil, "013d"},
+		{"v1.2", []IdxOption{WithVersion(IdxV12)}, "830008123d"},
+		{"v1.2_crc8", []IdxOption{WithVersion(IdxV12), WithChecksum(ChecksumCRC8)}, "80000912e63d"},
+	}
+	for _, c := range cases {
+		idx, err := NewIdx(c.opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := idx.EncodeWithVariant(0, uint8(10))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if c.want != "" && hex.EncodeToString(data) != c.want {
+			t.Fatalf("%s: got %x, want %s", c.name, data, c.want)
+		}
+		values, v, err := idx.DecodeVersioned(data)
+		if err != nil || values[0].(uint8) != 10 || v != IdxV12 {
+			t.Fatalf("%s: %v %v %v", c.name, values, v, err)
+		}
+		t.Logf("%-10s => %x", c.name, data)
+	}
+}
+
+// TestVersionInterop 默认 Idx 可解码任意已支持版本的块，VersionOf / Inspect 报告版本与是否标记。
+func TestVersionInterop(t *testing.T) {
+	plain, _ := NewIdx()
+	for _, opts := range [][]IdxOption{
+		nil,
+		{WithVersion(IdxV12)},
+		{WithVersion(IdxV12), WithChecksum(ChecksumCRC16)},
+		{WithVersion(IdxV12), WithMaxVariants(8), WithTagBits(2)},
+	} {
+		idx, err := NewIdx(opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pinned := idx.Version() != IdxVersionUnmarked
+		for v := 0; v < idx.MaxVariants(); v++ {
+			values := []any{uint32(1001), "eu", int64(-5)}
+			data, _ := idx.EncodeWithVariant(v, values...)
+			dec := plain
+			if idx.MaxVariants() != plain.MaxVariants() {
+				dec = idx
+			}
+			list, err := dec.Decode(data)
+			if err != nil || list[0].(uint32) != 1001 || list[1].(string) != "eu" || list[2].(int64) != -5 {
+				t.Fatalf("%v variant %d: %v %v", idx.Version(), v, list, err)
+			}
+			version, marked, err := dec.VersionOf(data)
+			if err != nil || version != IdxV12 || marked != pinned {
+				t.Fatalf("VersionOf: %v %v %v", version, marked, err)
+			}
+		}
+	}
+
+	idx, _ := NewIdx(WithVersion(IdxV12))
+	m, _ := New(WithIdx(idx))
+	s, _ := m.EncodeWithVariant(3, uint8(1), uint8(2))
+	info, err := m.Inspect(s)
+	if err != nil || info.Version != IdxV12 || !info.VersionMarked {
+		t.Fatalf("Inspect: %+v %v", info, err)
+	}
+	t.Logf("%q => binary %x, version %s (marked %v)", s, info.Binary, info.Version, info.VersionMarked)
+}
+
+// TestUnsupportedVersion 未知版本、保留位与缺失字段均明确报错。
+func TestUnsupportedVersion(t *testing.T) {
+	idx, _ := NewIdx()
+	// 以正确的 check 位构造版本 0x20 的块
+	block := func(b ...byte) []byte {
+		var x byte
+		for _, c := range b {
+			x ^= c
+		}
+		b[0] |= x & idx.checkMask
+		return b
+	}
+	_, err := idx.Decode(block(0x80, 0x00, 0x08, 0x20, 0x3d))
+	var ue *UnsupportedVersionError
+	if !errors.As(err, &ue) || ue.Version != 0x20 || !errors.Is(err, ErrUnsupportedVersion) {
+		t.Fatalf("version 0x20: %v", err)
+	}
+	t.Logf("version 0x20 => %v", err)
+
+	for name, data := range map[string][]byte{
+		"reserved_bit":    block(0x80, 0x00, 0x10, 0x12, 0x3d),
+		"xor_without_ver": block(0x80, 0x00, 0x00, 0x3d),
+		"missing_version": block(0x80, 0x00, 0x08),
+		"version_zero":    block(0x80, 0x00, 0x08, 0x00, 0x3d),
+		"missing_count":   block(0x80, 0x00, 0x0c, 0x12),
+	} {
+		if _, err := idx.Decode(data); err == nil {
+			t.Fatalf("%s: accepted", name)
+		} else {
+			t.Logf("%s => %v", name, err)
+		}
+	}
+
+	if _, err := NewIdx(WithVersion(0x20)); !errors.Is(err, ErrUnsupportedVersion) {
+		t.Fatalf("WithVersion(0x20): %v", err)
+	}
+}
+
+func TestIdxVersionNames(t *testing.T) {
+	for _, name := range []string{"v1.2", "1.2", "unmarked"} {
+		v, err := ParseIdxVersion(name)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		t.Logf("%s => %s (%#02x)", name, v, uint8(v))
+	}
+	if _, err := ParseIdxVersion("v2.0"); err == nil {
+		t.Fatal("expected unknown version error")
+	}
+	m, err := NewFromProfile(Profile{Version: "v1.2"}, nil)
+	if err != nil || m.Idx().Version() != IdxV12 {
+		t.Fatalf("profile version: %v", err)
+	}
+	err = (Profile{Version: "v9"}).Validate()
+	var pe *ProfileError
+	if !errors.As(err, &pe) || pe.Field != "version" {
+		t.Fatalf("profile validation: %v", err)
+	}
+}